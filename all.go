@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/discover"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
+)
+
+// statusError marks a project that failed in the summary table.
+const statusError projectStatus = "error"
+
+// runAll discovers every project below the repository root and processes each one with opts.
+// Failures do not stop the run; they are reported in the summary and turned into a final error.
+func runAll(out, errOut io.Writer, opts generateOptions) error {
+	rootPath := findRepositoryRoot(slog.Default(), opts.projectPath)
+	if rootPath == "" {
+		return fmt.Errorf("cannot find repository root")
+	}
+	Debugf("repository root: %s", rootPath)
+
+	gens := generator.All()
+	if opts.language != "" {
+		g, err := lookupGenerator(opts.language)
+		if err != nil {
+			return err
+		}
+		gens = []generator.Generator{g}
+	}
+	projects, err := discover.Find(rootPath, gens, discover.Options{Include: opts.include, Exclude: opts.exclude})
+	if err != nil {
+		return fmt.Errorf("error discovering projects: %w", err)
+	}
+	if len(projects) == 0 {
+		return fmt.Errorf("no projects found under %s", rootPath)
+	}
+	Infof("discovered %d project(s) under %s", len(projects), rootPath)
+
+	human := out
+	if opts.output == outputJSON {
+		human = errOut
+	}
+	results := processProjects(out, errOut, opts, projects)
+	for i, p := range projects {
+		results[i].path = p.RelPath
+		if results[i].err != nil {
+			Errorf("project %s failed: %v", p.RelPath, results[i].err)
+			results[i].status = statusError
+		}
+	}
+
+	if !(opts.check && opts.quiet) {
+		writeSummary(human, results)
+	}
+	return summaryError(opts, results)
+}
+
+// projectOutput is the buffered output and result of one project run.
+type projectOutput struct {
+	stdout bytes.Buffer
+	stderr bytes.Buffer
+	json   bytes.Buffer
+	res    projectResult
+}
+
+// processProjects runs every project with up to opts.jobs workers. Each project writes its output
+// and log records (including those of the generator, which logs to the project's logger) to its own
+// buffers, which are replayed to out/errOut in discovery order as soon as all earlier projects are
+// done, so stdout and stderr are identical whatever the number of jobs.
+func processProjects(out, errOut io.Writer, opts generateOptions, projects []discover.Project) []projectResult {
+	jobs := opts.jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	jobs = min(jobs, len(projects))
+	Debugf("processing %d project(s) with %d worker(s)", len(projects), jobs)
+
+	outputs := make([]*projectOutput, len(projects))
+	done := make([]chan struct{}, len(projects))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Go(func() {
+			for i := range indexes {
+				po := &projectOutput{}
+				r := newProjectRunner(&po.stdout, &po.stderr)
+				if opts.output == outputJSON {
+					r.out, r.jsonOut = &po.stderr, &po.json
+				}
+				po.res = r.runProject(opts, projects[i].Path)
+				outputs[i] = po
+				close(done[i])
+			}
+		})
+	}
+	go func() {
+		for i := range projects {
+			indexes <- i
+		}
+		close(indexes)
+	}()
+
+	results := make([]projectResult, len(projects))
+	for i := range projects {
+		<-done[i]
+		_, _ = errOut.Write(outputs[i].stderr.Bytes())
+		_, _ = out.Write(outputs[i].stdout.Bytes())
+		_, _ = out.Write(outputs[i].json.Bytes())
+		results[i] = outputs[i].res
+	}
+	wg.Wait()
+	return results
+}
+
+// writeSummary prints one aligned row per project.
+func writeSummary(out io.Writer, results []projectResult) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "\nPROJECT\tLANGUAGE\tSTATUS")
+	for _, r := range results {
+		lang := r.language
+		if lang == "" {
+			lang = "-"
+		}
+		status := string(r.status)
+		if r.err != nil {
+			status += ": " + r.err.Error()
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", r.path, lang, status)
+	}
+	_ = tw.Flush()
+}
+
+// summaryError turns aggregated results into the command error (or nil when everything succeeded).
+func summaryError(opts generateOptions, results []projectResult) error {
+	failed, stale := 0, 0
+	for _, r := range results {
+		switch {
+		case r.err != nil:
+			failed++
+		case opts.check && checkFailed(r.status):
+			stale++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d project(s) failed", failed, len(results))
+	}
+	if stale > 0 {
+		return &exitError{
+			code:   exitCodeStale,
+			err:    fmt.Errorf("%d of %d Dockerfile(s) missing or out of date", stale, len(results)),
+			silent: opts.quiet,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// newMonorepo creates a repository with two Go services and one .NET service.
+func newMonorepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(root, "services", "api", "go.mod"), sampleGoMod)
+	writeFile(t, filepath.Join(root, "services", "worker", "go.mod"), "module example.com/worker\n\ngo 1.23")
+	writeFile(t, filepath.Join(root, "services", "worker", ".dockerbuild"), "base:\n  image: alpine:3.20\n")
+	writeFile(t, filepath.Join(root, "src", "App", "App.csproj"), sampleCsproj)
+	return root
+}
+
+func TestGenerateAll(t *testing.T) {
+	root := newMonorepo(t)
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"generate", "--all", "-p", root, "--exclude", "src/**"})
+	out := captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	for _, rel := range []string{"services/api", "services/worker"} {
+		if _, err := os.Stat(filepath.Join(root, rel, "Dockerfile")); err != nil {
+			t.Fatalf("expected Dockerfile in %s: %v", rel, err)
+		}
+		if !strings.Contains(out, rel) {
+			t.Fatalf("expected %s in summary, got %q", rel, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "src", "App", "Dockerfile")); err == nil {
+		t.Fatalf("excluded project should not be generated")
+	}
+	data, _ := os.ReadFile(filepath.Join(root, "services", "worker", "Dockerfile")) // #nosec G304 - test file
+	if !strings.Contains(string(data), "FROM alpine:3.20 AS final") {
+		t.Fatalf("expected per-project .dockerbuild to apply, got %s", data)
+	}
+	if !strings.Contains(out, "PROJECT") || !strings.Contains(out, "generated") {
+		t.Fatalf("expected summary table, got %q", out)
+	}
+}
+
+func TestGenerateAllCheck(t *testing.T) {
+	root := newMonorepo(t)
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"--all", "-p", root, "--include", "services/api"})
+	captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+	})
+	cmd = newRootCmd()
+	cmd.SetArgs([]string{"--all", "-p", root, "--check"})
+	var err error
+	out := captureStdout(t, func() { err = cmd.Execute() })
+	var ee *exitError
+	if !errors.As(err, &ee) || ee.code != exitCodeStale {
+		t.Fatalf("expected stale exit error, got %v", err)
+	}
+	if !strings.Contains(out, "up-to-date") || !strings.Contains(out, "missing") {
+		t.Fatalf("expected mixed statuses in summary, got %q", out)
+	}
+}
+
+func TestGenerateAllJobsDeterministic(t *testing.T) {
+	root := newMonorepo(t)
+	for i := range 6 {
+		writeFile(t, filepath.Join(root, "extra", fmt.Sprintf("svc%d", i), "go.mod"),
+			fmt.Sprintf("module example.com/svc%d\n\ngo 1.23", i))
+	}
+	timestamp := regexp.MustCompile(`(?m)^time=\S+ `)
+	run := func(jobs string) (stdout, stderr string) {
+		cmd := newRootCmd()
+		cmd.SetArgs([]string{"--all", "-p", root, "--dry-run", "--verbose", "-j", jobs})
+		stderr = captureStderr(t, func() {
+			stdout = captureStdout(t, func() {
+				if err := cmd.Execute(); err != nil {
+					t.Fatalf("execute (jobs=%s): %v", jobs, err)
+				}
+			})
+		})
+		// Only the worker count, logged before any project runs, depends on -j.
+		var lines []string
+		for _, l := range strings.Split(timestamp.ReplaceAllString(stderr, ""), "\n") {
+			if !strings.Contains(l, "worker(s)") {
+				lines = append(lines, l)
+			}
+		}
+		return stdout, strings.Join(lines, "\n")
+	}
+	sequential, sequentialLog := run("1")
+	if !strings.Contains(sequentialLog, "Looking for project context file") {
+		t.Fatalf("expected generator logs on stderr, got:\n%s", sequentialLog)
+	}
+	for _, jobs := range []string{"4", "0"} {
+		parallel, parallelLog := run(jobs)
+		if parallel != sequential {
+			t.Fatalf("output with -j %s differs from sequential run:\n%s\nvs\n%s", jobs, parallel, sequential)
+		}
+		if parallelLog != sequentialLog {
+			t.Fatalf("stderr with -j %s differs from sequential run:\n%s\nvs\n%s", jobs, parallelLog, sequentialLog)
+		}
+	}
+}
+
+func TestGenerateCascadingConfig(t *testing.T) {
+	root := newMonorepo(t)
+	writeFile(t, filepath.Join(root, ".dockerbuild"), "base:\n  image: alpine:3.19\n  packages: [tzdata]\n")
+	writeFile(t, filepath.Join(root, "services", ".dockerbuild"), "base:\n  packages: [ca-certificates]\n")
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"--all", "-p", root, "-l", "go"})
+	captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	api, _ := os.ReadFile(filepath.Join(root, "services", "api", "Dockerfile"))       // #nosec G304 - test file
+	worker, _ := os.ReadFile(filepath.Join(root, "services", "worker", "Dockerfile")) // #nosec G304 - test file
+	packages := "tzdata \\\n    ca-certificates"
+	if !strings.Contains(string(api), "FROM alpine:3.19 AS final") || !strings.Contains(string(api), packages) {
+		t.Fatalf("expected root image and appended packages in api Dockerfile:\n%s", api)
+	}
+	if !strings.Contains(string(worker), "FROM alpine:3.20 AS final") || !strings.Contains(string(worker), packages) {
+		t.Fatalf("expected nearest image to win in worker Dockerfile:\n%s", worker)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/discover"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
+)
+
+// newCompletionCmd builds the 'completion' subcommand that prints a shell completion script.
+func newCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion bash|zsh|fish|powershell",
+		Short: "Print the shell completion script (paths, languages and config values are completed dynamically)",
+		Long: `completion prints a completion script for the given shell. Flag values are completed by
+calling dockerfile-gen itself, so --path suggests the projects of the current repository and
+--language the registered generators.
+
+  bash:       source <(dockerfile-gen completion bash)
+  zsh:        dockerfile-gen completion zsh > "${fpath[1]}/_dockerfile-gen"
+  fish:       dockerfile-gen completion fish | source
+  powershell: dockerfile-gen completion powershell | Out-String | Invoke-Expression`,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return writeCompletion(cmd.Root(), cmd.OutOrStdout(), args[0])
+		},
+	}
+}
+
+func writeCompletion(root *cobra.Command, out io.Writer, shell string) error {
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(out, true)
+	case "zsh":
+		return root.GenZshCompletion(out)
+	case "fish":
+		return root.GenFishCompletion(out, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(out)
+	}
+	return fmt.Errorf("unsupported shell '%s'", shell)
+}
+
+// registerCompletions registers the dynamic completions for the --path, --language and --profile
+// flags of cmd (flags cmd does not define are skipped).
+func registerCompletions(cmd *cobra.Command) {
+	_ = cmd.RegisterFlagCompletionFunc("path", completeProjectPaths)
+	_ = cmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	registerLanguageCompletion(cmd)
+}
+
+// completeProjectPaths suggests the project directories found under the repository root of the
+// working directory, together with their project files (.csproj, go.mod). Outside a repository it
+// falls back to the shell's file completion.
+func completeProjectPaths(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	rootPath := findRepositoryRoot(slog.Default(), cwd)
+	if rootPath == "" {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	projects, err := discover.Find(rootPath, generator.All(), discover.Options{})
+	if err != nil || len(projects) == 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	var out []string
+	add := func(path, desc string) {
+		rel, err := filepath.Rel(cwd, path)
+		if err != nil || !strings.HasPrefix(rel, toComplete) {
+			return
+		}
+		out = append(out, rel+"\t"+desc)
+	}
+	for _, p := range projects {
+		add(p.Path, p.Language+" project")
+		for _, pattern := range projectFilePatterns(p.Language) {
+			matches, _ := filepath.Glob(filepath.Join(p.Path, pattern))
+			for _, m := range matches {
+				add(m, p.Language+" project file")
+			}
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// projectFilePatterns returns the project file patterns advertised by the named generator.
+func projectFilePatterns(language string) []string {
+	g, ok := generator.Get(language)
+	if !ok {
+		return nil
+	}
+	if d, ok := g.(generator.Describer); ok {
+		return d.Describe().ProjectFiles
+	}
+	return nil
+}
+
+// completionConfig merges the .dockerbuild cascade of the --path value of cmd (repository root
+// down to the project directory), or the --config file when set, so completions can offer values
+// taken from the configuration. Files that fail to load are ignored.
+func completionConfig(cmd *cobra.Command) (config.Config, []config.Source) {
+	start := "."
+	if cmd != nil {
+		if p, err := cmd.Flags().GetString("path"); err == nil && p != "" {
+			start = p
+		}
+		if c, err := cmd.Flags().GetString("config"); err == nil && c != "" {
+			l, _, err := config.ParseLayer(c)
+			if err != nil {
+				return config.Config{}, nil
+			}
+			return config.Merge([]config.Layer{l})
+		}
+	}
+	dir := start
+	if fi, err := os.Stat(dir); err == nil && !fi.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	rootPath := findRepositoryRoot(slog.Default(), dir)
+	if rootPath == "" {
+		return config.Config{}, nil
+	}
+	var layers []config.Layer
+	for _, d := range configDirs(rootPath, dir) {
+		cfgPath, err := config.Find(d)
+		if err != nil || cfgPath == "" {
+			continue
+		}
+		if l, _, err := config.ParseLayer(cfgPath); err == nil {
+			layers = append(layers, l)
+		}
+	}
+	return config.Merge(layers)
+}
+
+// completeProfiles suggests the profiles defined by the project's .dockerbuild files.
+func completeProfiles(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	cfg, sources := completionConfig(cmd)
+	var out []string
+	for _, name := range cfg.ProfileNames() {
+		entry := name
+		for _, s := range sources {
+			if strings.HasPrefix(s.Key, "profiles."+name+".") {
+				entry += "\tfrom " + s.Files[len(s.Files)-1]
+				break
+			}
+		}
+		out = append(out, entry)
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// complete runs cobra's hidden __complete command and returns the suggestions it prints.
+func complete(t *testing.T, args ...string) string {
+	t.Helper()
+	var b bytes.Buffer
+	cmd := newRootCmd()
+	cmd.SetOut(&b)
+	cmd.SetArgs(append([]string{"__complete"}, args...))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("complete %v: %v", args, err)
+	}
+	return b.String()
+}
+
+func TestCompletePath(t *testing.T) {
+	root := newMonorepo(t)
+	t.Chdir(root)
+	out := complete(t, "generate", "-p", "")
+	for _, want := range []string{
+		filepath.Join("services", "api") + "\tgo project",
+		filepath.Join("services", "api", "go.mod") + "\tgo project file",
+		filepath.Join("src", "App", "App.csproj") + "\tdotnet project file",
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Fatalf("expected %q in completions, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(complete(t, "-p", "src"), "services") {
+		t.Fatalf("completions should be filtered by the typed prefix")
+	}
+}
+
+func TestCompleteLanguageFromNearestConfig(t *testing.T) {
+	root := newMonorepo(t)
+	writeFile(t, filepath.Join(root, "src", ".dockerbuild"), "language: go\n")
+	out := complete(t, "explain", "-p", filepath.Join(root, "src", "App"), "-l", "")
+	lines := strings.Split(out, "\n")
+	if !strings.HasPrefix(lines[0], "go\tfrom "+filepath.Join(root, "src", ".dockerbuild")) {
+		t.Fatalf("expected configured language first, got:\n%s", out)
+	}
+	if strings.Count(out, "go\t") != 1 || !strings.Contains(out, "dotnet\t") {
+		t.Fatalf("expected each language once, got:\n%s", out)
+	}
+}
+
+func TestCompletionCmd(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		var b bytes.Buffer
+		cmd := newRootCmd()
+		cmd.SetOut(&b)
+		cmd.SetArgs([]string{"completion", shell})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("completion %s: %v", shell, err)
+		}
+		if !strings.Contains(b.String(), "dockerfile-gen") {
+			t.Fatalf("expected %s script to reference the command", shell)
+		}
+	}
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"completion", "tcsh"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("expected unsupported shell to fail")
+	}
+}
+
+func TestCompleteProfiles(t *testing.T) {
+	root := newMonorepo(t)
+	writeFile(t, filepath.Join(root, ".dockerbuild"), "profiles:\n  prod:\n    base:\n      image: alpine:3.21\n")
+	writeFile(t, filepath.Join(root, "services", "api", ".dockerbuild"),
+		"profiles:\n  debug:\n    base:\n      packages: [strace]\n")
+	out := complete(t, "-p", filepath.Join(root, "services", "api"), "--profile", "")
+	for _, want := range []string{
+		"debug\tfrom " + filepath.Join(root, "services", "api", ".dockerbuild"),
+		"prod\tfrom " + filepath.Join(root, ".dockerbuild"),
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Fatalf("expected %q in completions, got:\n%s", want, out)
+		}
+	}
+}
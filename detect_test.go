@@ -2,6 +2,7 @@
 package main
 
 import (
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -63,23 +64,19 @@ func TestDotnetDetectAndGenerate(t *testing.T) {
 	if err := os.Mkdir(filepath.Join(root, ".git"), 0o750); err != nil {
 		t.Fatalf("git dir: %v", err)
 	}
-	proj, additional, err := gen.Load(projPath, root)
+	proj, additional, err := gen.Load(slog.Default(), projPath, root)
 	if err != nil {
 		t.Fatalf("load: %v", err)
 	}
 	if len(additional) != 0 {
 		t.Fatalf("expected no additional files, got %d", len(additional))
 	}
-	dest := filepath.Join(dir, "Dockerfile")
+	var b strings.Builder
 	cfg := config.Default()
-	if err := gen.GenerateDockerfile(proj, additional, dest, cfg); err != nil {
+	if err := gen.GenerateDockerfile(slog.Default(), proj, additional, &b, cfg); err != nil {
 		t.Fatalf("generate: %v", err)
 	}
-	data, err := os.ReadFile(dest) // #nosec G304 - reading generated file in test
-	if err != nil {
-		t.Fatalf("read dockerfile: %v", err)
-	}
-	content := string(data)
+	content := b.String()
 	if !strings.Contains(content, "dotnet publish") {
 		t.Fatalf("dockerfile missing dotnet publish step")
 	}
@@ -101,23 +98,19 @@ func TestGoDetectAndGenerate(t *testing.T) {
 	if err := os.Mkdir(filepath.Join(root, ".git"), 0o750); err != nil {
 		t.Fatalf("git dir: %v", err)
 	}
-	proj, additional, err := gen.Load(dir, root)
+	proj, additional, err := gen.Load(slog.Default(), dir, root)
 	if err != nil {
 		t.Fatalf("load: %v", err)
 	}
 	if len(additional) != 0 {
 		t.Fatalf("expected no additional files, got %d", len(additional))
 	}
-	dest := filepath.Join(dir, "Dockerfile")
+	var b strings.Builder
 	cfg := config.Default()
-	if err := gen.GenerateDockerfile(proj, additional, dest, cfg); err != nil {
+	if err := gen.GenerateDockerfile(slog.Default(), proj, additional, &b, cfg); err != nil {
 		t.Fatalf("generate: %v", err)
 	}
-	data, err := os.ReadFile(dest) // #nosec G304 - reading generated file in test
-	if err != nil {
-		t.Fatalf("read dockerfile: %v", err)
-	}
-	if !strings.Contains(string(data), "GO_VERSION") {
+	if !strings.Contains(b.String(), "GO_VERSION") {
 		t.Fatalf("dockerfile missing GO_VERSION ARG")
 	}
 }
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
+)
+
+// newExplainCmd builds the 'explain' subcommand that reports every generation decision without writing.
+func newExplainCmd() *cobra.Command {
+	var opts generateOptions
+	cmd := &cobra.Command{
+		Use:   "explain",
+		Short: "Explain how the Dockerfile for a project would be generated (language, root, config, graph, images)",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			p, err := newProjectRunner(os.Stdout, os.Stderr).resolveProject(opts, opts.projectPath)
+			if err != nil {
+				return err
+			}
+			writeExplain(os.Stdout, p)
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVarP(&opts.projectPath, "path", "p", ".",
+		"Path to the project (directory, .csproj, or go.mod). Defaults to current directory.")
+	f.StringVarP(&opts.dockerfileName, "dockerfile", "f", "Dockerfile", "Name of the Dockerfile to generate")
+	addLanguageFlag(f, &opts.language)
+	addConfigFlags(f, &opts)
+	registerCompletions(cmd)
+	return cmd
+}
+
+// writeExplain prints the structured decision report for a resolved project.
+func writeExplain(out io.Writer, p *resolvedProject) {
+	section := func(title string) { _, _ = fmt.Fprintf(out, "\n%s\n", title) }
+	kv := func(rows ...[2]string) {
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, r := range rows {
+			_, _ = fmt.Fprintf(tw, "  %s:\t%s\n", r[0], r[1])
+		}
+		_ = tw.Flush()
+	}
+
+	absPath, _ := filepath.Abs(p.path)
+	_, _ = fmt.Fprintf(out, "Explaining generation for %s\n", p.path)
+
+	section("Repository root")
+	kv([2]string{"path", p.rootPath},
+		[2]string{"found", fmt.Sprintf("nearest directory containing .git, searching upward from %s", absPath)})
+
+	section("Configuration")
+	if p.trace.configFlag {
+		_, _ = fmt.Fprintln(out, "  files (from --config):")
+	} else {
+		_, _ = fmt.Fprintln(out, "  files (repository root first):")
+	}
+	files := make([][2]string, len(p.trace.configs))
+	for i, c := range p.trace.configs {
+		files[i] = [2]string{"  " + c.path, c.status}
+	}
+	kv(files...)
+	for _, problem := range p.trace.configProblems {
+		_, _ = fmt.Fprintf(out, "  problem: %s\n", problem)
+	}
+	if p.trace.profile != "" {
+		_, _ = fmt.Fprintf(out, "  profile: %s (from %s)\n", p.trace.profile, p.trace.profileSource)
+	}
+	if len(p.trace.configSources) == 0 {
+		_, _ = fmt.Fprintln(out, "  effective values: none (defaults used)")
+	} else {
+		_, _ = fmt.Fprintln(out, "  effective values:")
+		var values [][2]string
+		for _, s := range p.trace.configSources {
+			if strings.HasPrefix(s.Key, "profiles.") {
+				continue // profile definitions; the selected one is already applied
+			}
+			values = append(values, [2]string{"  " + s.Key, fmt.Sprintf("%s (from %s)", s.Value, strings.Join(s.Files, ", "))})
+		}
+		kv(values...)
+	}
+
+	section("Language")
+	kv([2]string{"resolved", p.language}, [2]string{"source", p.trace.languageSource})
+
+	section("Project")
+	if e, ok := p.gen.(generator.Explainer); ok {
+		for _, line := range e.ExplainProject(p.data) {
+			_, _ = fmt.Fprintf(out, "  %s\n", line)
+		}
+	} else {
+		_, _ = fmt.Fprintf(out, "  (generator %s does not describe its projects)\n", p.gen.Name())
+	}
+
+	section(fmt.Sprintf("Additional context files (%d)", len(p.additional)))
+	for _, a := range p.additional {
+		reason := a.Reason
+		if reason == "" {
+			reason = "provided by generator"
+		}
+		_, _ = fmt.Fprintf(out, "  %s\n    %s\n", a.GetRelativePath(), reason)
+	}
+
+	section("Images")
+	if d, ok := p.gen.(generator.Defaulter); ok {
+		defaults := d.DefaultImages()
+		kv([2]string{"base", imageDecision(p.cfg.Base.Image, defaults.Base)},
+			[2]string{"base-build", imageDecision(p.cfg.BaseBuild.Image, defaults.BaseBuild)})
+	} else {
+		kv([2]string{"base", p.cfg.Base.Image}, [2]string{"base-build", p.cfg.BaseBuild.Image})
+	}
+
+	section("Output")
+	tmpl := "embedded " + p.language + " template"
+	if p.trace.templateSource != "" {
+		tmpl = fmt.Sprintf("%s (from %s)", p.cfg.Template, p.trace.templateSource)
+	}
+	kv([2]string{"template", tmpl}, [2]string{"dockerfile", p.dest})
+}
+
+func imageDecision(configured, def string) string {
+	if configured == "" {
+		return def + " (default applied)"
+	}
+	return configured + " (from config; default " + def + ")"
+}
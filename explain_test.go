@@ -0,0 +1,63 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestExplainDotnet(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(root, "Directory.Build.props"), "<Project/>")
+	writeFile(t, filepath.Join(root, "src", "App", "App.csproj"), `<Project><ItemGroup><ProjectReference Include="../Lib/Lib.csproj" /></ItemGroup></Project>`)
+	writeFile(t, filepath.Join(root, "src", "Lib", "Lib.csproj"), sampleCsproj)
+	writeFile(t, filepath.Join(root, ".dockerbuild"), "base:\n  image: org:1\n  packages: [tzdata]\n")
+	writeFile(t, filepath.Join(root, "src", "App", ".dockerbuild"), "base:\n  image: custom:1\n  packages: [curl]\n")
+
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"explain", "-p", filepath.Join(root, "src", "App")})
+	out := captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("explain: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"autodetect via dotnet generator Detect",
+		"base.image:     custom:1 (from " + filepath.Join(root, "src", "App", ".dockerbuild") + ")",
+		"base.packages:  tzdata, curl (from " + filepath.Join(root, ".dockerbuild") + ", " +
+			filepath.Join(root, "src", "App", ".dockerbuild") + ")",
+		"src/App/App.csproj",
+		"    src/Lib/Lib.csproj (net9.0)",
+		"found walking up from src/App/App.csproj",
+		"custom:1 (from config",
+		"(default applied)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in explain output:\n%s", want, out)
+		}
+	}
+	if !regexp.MustCompile(regexp.QuoteMeta(filepath.Join(root, "src", ".dockerbuild")) + `:\s+not found`).MatchString(out) {
+		t.Fatalf("expected intermediate config to be listed as not found:\n%s", out)
+	}
+}
+
+func TestExplainLanguageFlag(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(dir, "go.mod"), sampleGoMod)
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"explain", "-p", dir, "-l", "go"})
+	out := captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("explain: %v", err)
+		}
+	})
+	if !strings.Contains(out, "--language flag") || !strings.Contains(out, "module app") {
+		t.Fatalf("unexpected explain output:\n%s", out)
+	}
+	if !strings.Contains(out, "not found") || !strings.Contains(out, "effective values: none (defaults used)") {
+		t.Fatalf("expected missing config to be reported:\n%s", out)
+	}
+}
@@ -0,0 +1,711 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/common"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/unidiff"
+)
+
+// generateOptions holds the flags controlling how a Dockerfile is produced.
+type generateOptions struct {
+	projectPath    string
+	dockerfileName string
+	language       string
+	dryRun         bool
+	check          bool
+	quiet          bool
+	all            bool
+	jobs           int
+	include        []string
+	exclude        []string
+	output         string
+	since          string
+	strict         bool
+	profile        string
+	configPath     string
+	template       string
+	// replacedConfig is a config file left out of the cascade because it is being rewritten
+	// (init --force), so a file that no longer loads can still be replaced.
+	replacedConfig string
+	// changed holds the absolute paths reported by git for --since; nil when --since is not set.
+	changed map[string]bool
+}
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+
+	// stdoutDest as the --dockerfile value streams the Dockerfile to stdout.
+	stdoutDest = "-"
+
+	// profileEnvVar selects a configuration profile when --profile is not given.
+	profileEnvVar = "DOCKERFILE_GEN_PROFILE"
+)
+
+// projectStatus describes the outcome of processing a single project.
+type projectStatus string
+
+const (
+	statusGenerated projectStatus = "generated"
+	statusUnchanged projectStatus = "unchanged"
+	statusChanged   projectStatus = "changed"
+	statusUpToDate  projectStatus = "up-to-date"
+	statusStale     projectStatus = "stale"
+	statusMissing   projectStatus = "missing"
+	// statusUnaffected marks a project skipped by --since because none of its inputs changed.
+	statusUnaffected projectStatus = "unaffected"
+)
+
+// projectRunner carries the output streams and logger for processing projects. Parallel runs
+// give each project its own buffered runner so output can be replayed in a stable order.
+type projectRunner struct {
+	out           io.Writer // human-readable results
+	errOut        io.Writer // warnings and log records
+	jsonOut       io.Writer // machine-readable reports (nil unless --output json)
+	dockerfileOut io.Writer // rendered Dockerfile when the destination is stdout ("-f -")
+	log           *slog.Logger
+}
+
+func newProjectRunner(out, errOut io.Writer) *projectRunner {
+	return &projectRunner{out: out, errOut: errOut, log: newLogger(errOut)}
+}
+
+func (r *projectRunner) debugf(format string, a ...any) { r.log.Debug(fmt.Sprintf(format, a...)) }
+func (r *projectRunner) infof(format string, a ...any)  { r.log.Info(fmt.Sprintf(format, a...)) }
+func (r *projectRunner) warnf(format string, a ...any)  { r.log.Warn(fmt.Sprintf(format, a...)) }
+
+// projectResult summarizes what happened to one project.
+type projectResult struct {
+	path     string
+	language string
+	dest     string
+	status   projectStatus
+	changed  bool
+	err      error
+	project  *resolvedProject // nil when resolution failed
+}
+
+// resolvedProject is a generation target with its language, config and loaded project data.
+type resolvedProject struct {
+	path       string
+	dir        string
+	rootPath   string
+	language   string
+	gen        generator.Generator
+	cfg        config.Config
+	data       generator.ProjectData
+	additional []common.AdditionalFilePath
+	dest       string
+	trace      resolveTrace
+	warnings   []string
+}
+
+// resolveTrace records why each resolution decision was taken (reported by the explain command).
+type resolveTrace struct {
+	configs        []configTrace // candidate files, repository root first
+	configInputs   []string      // every config path that affects the result, existing or not
+	configFlag     bool          // configs come from --config instead of the cascade
+	configSources  []config.Source
+	configProblems []string
+	profile        string // selected profile, empty when none
+	profileSource  string
+	languageSource string
+	templateSource string // empty when the generator's embedded template is used
+}
+
+// configTrace is the outcome of loading one candidate configuration file.
+type configTrace struct {
+	path   string
+	status string
+}
+
+// sourceOf returns the file the effective value of key came from (the nearest one for lists).
+func (t resolveTrace) sourceOf(key string) string {
+	for _, s := range t.configSources {
+		if s.Key == key {
+			return s.Files[len(s.Files)-1]
+		}
+	}
+	return ""
+}
+
+// configDirs returns the directories whose configuration files cascade onto a project in dir,
+// from rootPath down to dir. Only dir is returned when it is not below rootPath.
+func configDirs(rootPath, dir string) []string {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return []string{dir}
+	}
+	rel, err := filepath.Rel(rootPath, absDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return []string{absDir}
+	}
+	dirs := []string{rootPath}
+	if rel != "." {
+		current := rootPath
+		for _, segment := range strings.Split(rel, string(filepath.Separator)) {
+			current = filepath.Join(current, segment)
+			dirs = append(dirs, current)
+		}
+	}
+	return dirs
+}
+
+// configFiles returns the configuration files to merge for a project, repository root first: the
+// --config file when set, otherwise the file found in each directory of the cascade. Every path
+// that would change the result when created or edited is recorded in trace.configInputs.
+func (r *projectRunner) configFiles(opts generateOptions, rootPath, projectDirectory string,
+	trace *resolveTrace) ([]string, error) {
+	if opts.configPath != "" {
+		path, err := filepath.Abs(opts.configPath)
+		if err != nil {
+			path = opts.configPath
+		}
+		if fi, err := os.Stat(path); err != nil || fi.IsDir() {
+			return nil, fmt.Errorf("config file not found: %s", opts.configPath)
+		}
+		trace.configFlag = true
+		trace.configInputs = []string{path}
+		return []string{path}, nil
+	}
+	var files []string
+	for _, dir := range configDirs(rootPath, projectDirectory) {
+		for _, name := range config.FileNames {
+			trace.configInputs = append(trace.configInputs, filepath.Join(dir, name))
+		}
+		found, err := config.Find(dir)
+		if err != nil {
+			return nil, err
+		}
+		if found != "" && opts.replacedConfig != "" && sameFile(found, opts.replacedConfig) {
+			r.debugf("ignoring %s, which is being replaced", found)
+			found = ""
+		}
+		if found == "" {
+			r.debugf("no config file found in %s", dir)
+			trace.configs = append(trace.configs,
+				configTrace{path: filepath.Join(dir, config.DefaultDockerBuildFileName), status: "not found"})
+			continue
+		}
+		files = append(files, found)
+	}
+	return files, nil
+}
+
+// sameFile reports whether a and b name the same path once made absolute.
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// loadConfig merges every configuration file from the repository root down to the project
+// directory (or the --config file). Unreadable or invalid files are skipped with a warning, or fail
+// the run with --strict. It reports whether at least one file was loaded and the warnings to
+// attach to the result.
+func (r *projectRunner) loadConfig(opts generateOptions, rootPath, projectDirectory string,
+	trace *resolveTrace) (config.Config, bool, []string, error) {
+	files, err := r.configFiles(opts, rootPath, projectDirectory, trace)
+	if err != nil {
+		return config.Config{}, false, nil, err
+	}
+	var layers []config.Layer
+	var warnings []string
+	for _, cfgPath := range files {
+		ct := configTrace{path: cfgPath}
+		layer, diags, err := config.ParseLayer(cfgPath)
+		switch {
+		case errors.Is(err, config.ErrUnsetVariable), errors.Is(err, config.ErrInvalidReference),
+			errors.Is(err, config.ErrInvalidStages):
+			return config.Config{}, false, nil, fmt.Errorf("invalid config: %w", err)
+		case err != nil && opts.strict:
+			return config.Config{}, false, nil, fmt.Errorf("invalid config (--strict): %w", err)
+		case err != nil:
+			_, _ = fmt.Fprintf(r.errOut, "Warning: failed to load config: %v\n", err)
+			r.warnf("failed to load config file %s: %v", cfgPath, err)
+			ct.status = fmt.Sprintf("failed to load (%v); skipped", err)
+			warnings = append(warnings, fmt.Sprintf("failed to load config: %v", err))
+		case len(diags) > 0 && opts.strict:
+			return config.Config{}, false, nil,
+				fmt.Errorf("invalid config (--strict): %w", &config.ValidationError{Diagnostics: diags})
+		default:
+			for _, d := range diags {
+				_, _ = fmt.Fprintf(r.errOut, "Warning: %s\n", d)
+				r.warnf("config problem: %s", d)
+				trace.configProblems = append(trace.configProblems, d.String())
+				warnings = append(warnings, d.String())
+			}
+			layers = append(layers, layer)
+			r.debugf("loaded config from %s", cfgPath)
+			ct.status = "loaded"
+			if len(diags) > 0 {
+				ct.status = fmt.Sprintf("loaded with %d problem(s); invalid entries ignored", len(diags))
+			}
+		}
+		trace.configs = append(trace.configs, ct)
+	}
+	cfg, sources := config.Default(), []config.Source(nil)
+	if len(layers) > 0 {
+		cfg, sources = config.Merge(layers)
+	} else {
+		r.debugf("no config file loaded (using defaults)")
+	}
+
+	profile, profileSource := opts.profile, "--profile flag"
+	if profile == "" {
+		profile, profileSource = os.Getenv(profileEnvVar), profileEnvVar+" environment variable"
+	}
+	if profile != "" {
+		var err error
+		if cfg, sources, err = config.ApplyProfile(cfg, sources, profile); err != nil {
+			return config.Config{}, false, nil, err
+		}
+		trace.profile = profile
+		trace.profileSource = profileSource
+		r.debugf("applied profile %s (from %s)", profile, profileSource)
+	}
+
+	trace.configSources = sources
+	for _, src := range sources {
+		r.debugf("config %s = %q from %s", src.Key, src.Value, strings.Join(src.Files, ", "))
+	}
+
+	switch {
+	case opts.template != "":
+		cfg.Template = opts.template
+		if abs, err := filepath.Abs(opts.template); err == nil {
+			cfg.Template = abs
+		}
+		trace.templateSource = "--template flag"
+	case cfg.Template != "":
+		trace.templateSource = "'template' key in " + trace.sourceOf("template")
+	}
+	return cfg, len(layers) > 0, warnings, nil
+}
+
+// resolveProject runs repository root lookup, config loading, language resolution and project loading.
+func (r *projectRunner) resolveProject(opts generateOptions, projectPath string) (*resolvedProject, error) {
+	if projectPath == "" {
+		// Should not happen because default is set, but guard anyway.
+		projectPath = "."
+	}
+	r.debugf("project path provided/resolved: %s", projectPath)
+
+	if _, err := os.Stat(projectPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("project path not found: %s", projectPath)
+	}
+
+	rootPath := findRepositoryRoot(r.log, projectPath)
+	if rootPath == "" {
+		return nil, fmt.Errorf("cannot find repository root")
+	}
+	r.debugf("repository root: %s", rootPath)
+
+	projectDirectory := projectPath
+	if fi, err := os.Stat(projectDirectory); err == nil && !fi.IsDir() {
+		projectDirectory = filepath.Dir(projectDirectory)
+	}
+	r.debugf("project directory resolved: %s", projectDirectory)
+
+	var trace resolveTrace
+	cfg, configLoaded, warnings, err := r.loadConfig(opts, rootPath, projectDirectory, &trace)
+	if err != nil {
+		return nil, err
+	}
+
+	language := opts.language
+	if language != "" {
+		trace.languageSource = "--language flag"
+	}
+	// If language flag not set, use config only if a config file was loaded
+	if language == "" && configLoaded && cfg.Language != "" {
+		language = strings.ToLower(cfg.Language)
+		r.debugf("language set from config: %s", language)
+		trace.languageSource = "'language' key in " + trace.sourceOf("language")
+	}
+
+	// Autodetect if still empty
+	if language == "" {
+		var attempts []string
+		for _, g := range generator.All() {
+			ok, _ := g.Detect(projectPath)
+			r.debugf("detect attempt with generator %s => %v", g.Name(), ok)
+			attempts = append(attempts, fmt.Sprintf("%s=%v", g.Name(), ok))
+			if ok {
+				language = g.Name()
+				break
+			}
+		}
+		trace.languageSource = fmt.Sprintf("autodetect via %s generator Detect (tried in registration order: %s)",
+			language, strings.Join(attempts, ", "))
+	}
+	if language == "" {
+		return nil, fmt.Errorf("could not detect language; provide -l / --language")
+	}
+	r.debugf("final language resolved: %s", language)
+
+	gen, err := lookupGenerator(language)
+	if err != nil {
+		return nil, err
+	}
+	r.debugf("using generator: %s", gen.Name())
+
+	project, additional, err := gen.Load(r.log, projectPath, rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading project: %w", err)
+	}
+	r.debugf("loaded project; additional files: %d", len(additional))
+	for _, a := range additional {
+		r.debugf("additional context file: %s", a.GetRelativePath())
+	}
+	if c, ok := gen.(generator.Checker); ok {
+		for _, w := range c.Check(project, cfg) {
+			_, _ = fmt.Fprintf(r.errOut, "Warning: %s\n", w)
+			r.warnf("%s", w)
+			warnings = append(warnings, w)
+		}
+	}
+	if w := templateDrift(gen, cfg.Template); w != "" {
+		_, _ = fmt.Fprintf(r.errOut, "Warning: %s\n", w)
+		r.warnf("%s", w)
+		warnings = append(warnings, w)
+	}
+
+	dest := filepath.Join(projectDirectory, opts.dockerfileName)
+	if opts.dockerfileName == stdoutDest {
+		dest = stdoutDest
+	}
+	r.debugf("output Dockerfile path: %s", dest)
+
+	return &resolvedProject{
+		path:       projectPath,
+		dir:        projectDirectory,
+		rootPath:   rootPath,
+		language:   language,
+		gen:        gen,
+		cfg:        cfg,
+		data:       project,
+		additional: additional,
+		dest:       dest,
+		trace:      trace,
+		warnings:   warnings,
+	}, nil
+}
+
+// inputs returns every file whose content affects p's Dockerfile: the configuration files from
+// the repository root down to the project (whether or not they exist), the custom template and
+// hook files, the generator's project files and the additional context files.
+func (p *resolvedProject) inputs() []string {
+	files := slices.Clone(p.trace.configInputs)
+	if p.cfg.Template != "" {
+		files = append(files, p.cfg.Template)
+	}
+	for _, h := range p.cfg.Hooks {
+		if h.File != "" {
+			files = append(files, h.File)
+		}
+	}
+	if l, ok := p.gen.(generator.InputLister); ok {
+		files = append(files, l.ProjectInputs(p.data)...)
+	}
+	for _, a := range p.additional {
+		files = append(files, a.Path)
+	}
+	for i, f := range files {
+		if abs, err := filepath.Abs(f); err == nil {
+			files[i] = abs
+		}
+	}
+	slices.Sort(files)
+	return slices.Compact(files)
+}
+
+// affected reports whether any of p's inputs, or its Dockerfile, is in the changed set. Changed
+// paths the generator would search for context files count too, so a deleted Directory.Build.props
+// (no longer among the inputs) still affects the project.
+func (p *resolvedProject) affected(changed map[string]bool) bool {
+	files := p.inputs()
+	if abs, err := filepath.Abs(p.dest); err == nil && p.dest != stdoutDest {
+		files = append(files, abs)
+	}
+	for _, f := range files {
+		if changed[f] {
+			return true
+		}
+	}
+	if m, ok := p.gen.(generator.InputMatcher); ok {
+		for f := range changed {
+			if m.MatchesInput(p.data, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// render generates the Dockerfile content for p in memory.
+func (r *projectRunner) render(p *resolvedProject) ([]byte, error) {
+	var b bytes.Buffer
+	if err := p.gen.GenerateDockerfile(r.log, p.data, p.additional, &b, p.cfg); err != nil {
+		return nil, fmt.Errorf("error generating Dockerfile: %w", err)
+	}
+	r.debugf("rendered Dockerfile (%d bytes)", b.Len())
+	return b.Bytes(), nil
+}
+
+// readExisting returns the current destination content and whether the file exists.
+func readExisting(dest string) ([]byte, bool) {
+	if _, err := os.Stat(dest); err != nil {
+		return nil, false
+	}
+	oldBytes, _ := os.ReadFile(dest) // #nosec G304 - dest is within project directory
+	return oldBytes, true
+}
+
+// runProject processes a single project according to opts, writing human-readable output to r.out.
+// The returned result always carries the error (if any) so callers can aggregate failures.
+func (r *projectRunner) runProject(opts generateOptions, projectPath string) projectResult {
+	res := projectResult{path: projectPath}
+	defer func() {
+		if r.jsonOut != nil {
+			writeReport(r.jsonOut, res)
+		}
+	}()
+	p, err := r.resolveProject(opts, projectPath)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	res.project = p
+	res.language = p.language
+	res.dest = p.dest
+
+	if opts.changed != nil && !p.affected(opts.changed) {
+		res.status = statusUnaffected
+		_, _ = fmt.Fprintf(r.out, "Skipped %s: no inputs changed since %s\n", p.path, opts.since)
+		r.infof("skipping %s: not affected by changes since %s", p.path, opts.since)
+		return res
+	}
+
+	switch {
+	case opts.check:
+		res.status, res.err = r.checkProject(opts, p)
+		res.changed = checkFailed(res.status)
+	case opts.dryRun:
+		res.status, res.err = r.dryRunProject(p)
+		res.changed = res.status == statusChanged
+	default:
+		res.status, res.changed, res.err = r.writeProject(opts, p)
+	}
+	return res
+}
+
+func (r *projectRunner) writeProject(opts generateOptions, p *resolvedProject) (projectStatus, bool, error) {
+	r.infof("generating Dockerfile for %s (%s)", p.path, p.language)
+	newBytes, err := r.render(p)
+	if err != nil {
+		return "", false, err
+	}
+	if p.dest == stdoutDest {
+		if _, err := r.dockerfileOut.Write(newBytes); err != nil {
+			return "", false, fmt.Errorf("error writing Dockerfile to stdout: %w", err)
+		}
+		r.infof("generation complete: written to stdout")
+		return statusGenerated, true, nil
+	}
+	oldBytes, existed := readExisting(p.dest)
+	// #nosec G306 - Dockerfiles are meant to be readable by the build tooling.
+	if err := os.WriteFile(p.dest, newBytes, 0o644); err != nil {
+		return "", false, fmt.Errorf("error writing Dockerfile: %w", err)
+	}
+	_, _ = fmt.Fprintf(r.out, "Successfully generated %s (%s) for project %s\n", opts.dockerfileName, p.language, p.path)
+	r.infof("generation complete: %s", p.dest)
+	return statusGenerated, !existed || !bytes.Equal(oldBytes, newBytes), nil
+}
+
+func (r *projectRunner) dryRunProject(p *resolvedProject) (projectStatus, error) {
+	r.infof("running in dry-run mode")
+	newBytes, err := r.render(p)
+	if err != nil {
+		return "", fmt.Errorf("dry-run: %w", err)
+	}
+	oldBytes, _ := readExisting(p.dest)
+	r.debugf("existing Dockerfile size: %d bytes, new size: %d bytes", len(oldBytes), len(newBytes))
+	if string(oldBytes) == string(newBytes) {
+		_, _ = fmt.Fprintf(r.out, "Dry run: no changes. %s is up to date.\n", p.dest)
+		r.infof("no changes detected compared to existing %s", p.dest)
+		return statusUnchanged, nil
+	}
+	diff := unidiff.Unified(string(oldBytes), string(newBytes), p.dest)
+	_, _ = fmt.Fprintln(r.out, diff)
+	_, _ = fmt.Fprintln(r.out, "Dry run: no file written.")
+	r.infof("differences displayed; not writing file")
+	return statusChanged, nil
+}
+
+// checkProject compares the on-disk Dockerfile with freshly generated content without writing anything.
+func (r *projectRunner) checkProject(opts generateOptions, p *resolvedProject) (projectStatus, error) {
+	r.infof("running in check mode")
+	newBytes, err := r.render(p)
+	if err != nil {
+		return "", fmt.Errorf("check: %w", err)
+	}
+	oldBytes, exists := readExisting(p.dest)
+	status := statusUpToDate
+	switch {
+	case !exists:
+		status = statusMissing
+	case string(oldBytes) != string(newBytes):
+		status = statusStale
+	}
+	r.debugf("check result for %s: %s", p.dest, status)
+	if !opts.quiet {
+		_, _ = fmt.Fprintf(r.out, "%-10s %s\n", strings.ToUpper(string(status)), p.dest)
+	}
+	return status, nil
+}
+
+// runGenerate is the entry point shared by the root and generate commands.
+// With --output json, reports go to out and human-readable messages move to errOut.
+func runGenerate(out, errOut io.Writer, opts generateOptions) error {
+	switch opts.output {
+	case "", outputText, outputJSON:
+	default:
+		return fmt.Errorf("unsupported output format '%s' (expected %s or %s)", opts.output, outputText, outputJSON)
+	}
+	if opts.dockerfileName == stdoutDest && (opts.all || opts.check || opts.dryRun || opts.output == outputJSON) {
+		return fmt.Errorf("--dockerfile - (stdout) cannot be combined with --all, --check, --dry-run or --output json")
+	}
+	if opts.language != "" {
+		if _, err := lookupGenerator(opts.language); err != nil {
+			return err
+		}
+	}
+	if opts.check && opts.quiet && logLevel.Level() == slog.LevelInfo {
+		logLevel.Set(slog.LevelWarn) // only warnings and errors; --verbose still shows everything
+	}
+	if opts.since != "" {
+		changed, err := changedSet(opts.projectPath, opts.since)
+		if err != nil {
+			return err
+		}
+		opts.changed = changed
+	}
+	if opts.all {
+		return runAll(out, errOut, opts)
+	}
+	r := newProjectRunner(out, errOut)
+	switch {
+	case opts.output == outputJSON:
+		r.out, r.jsonOut = errOut, out
+	case opts.dockerfileName == stdoutDest:
+		r.out, r.dockerfileOut = errOut, out
+	}
+	res := r.runProject(opts, opts.projectPath)
+	if res.err != nil {
+		return res.err
+	}
+	if opts.check && checkFailed(res.status) {
+		return &exitError{
+			code:   exitCodeStale,
+			err:    fmt.Errorf("%s is %s; regenerate it with dockerfile-gen", opts.dockerfileName, res.status),
+			silent: opts.quiet,
+		}
+	}
+	return nil
+}
+
+// addGenerateFlags registers the generation flags on fs, binding them to opts.
+func addGenerateFlags(fs *pflag.FlagSet, opts *generateOptions) {
+	fs.StringVarP(&opts.projectPath, "path", "p", ".",
+		"Path to the project (directory, .csproj, or go.mod). Defaults to current directory.")
+	fs.StringVarP(&opts.dockerfileName, "dockerfile", "f", "Dockerfile",
+		"Name of the Dockerfile to generate ('-' writes it to stdout)")
+	addLanguageFlag(fs, &opts.language)
+	fs.BoolVarP(&opts.dryRun, "dry-run", "d", false, "Do not write file; show diff between existing and generated content")
+	fs.BoolVar(&opts.check, "check", false,
+		fmt.Sprintf("Do not write file; exit with code %d if the Dockerfile is missing or out of date", exitCodeStale))
+	fs.BoolVarP(&opts.quiet, "quiet", "q", false, "With --check, print only warnings and errors (no per-file summary or progress logs)")
+	fs.BoolVar(&opts.all, "all", false,
+		"Discover every project under the repository root and process each one")
+	fs.IntVarP(&opts.jobs, "jobs", "j", 1,
+		"With --all, number of projects processed concurrently (0 = number of CPUs)")
+	fs.StringVarP(&opts.output, "output", "o", outputText,
+		"Output format: text, or json (one JSON document per project on stdout; messages go to stderr)")
+	fs.StringSliceVar(&opts.include, "include", nil,
+		"With --all, only process project directories matching these globs (relative to repo root, '**' allowed)")
+	fs.StringSliceVar(&opts.exclude, "exclude", nil,
+		"With --all, skip project directories (and their subtrees) matching these globs")
+	addConfigFlags(fs, opts)
+	fs.BoolVar(&opts.strict, "strict", false,
+		"Fail when "+config.DefaultDockerBuildFileName+" has unknown keys, wrong types or invalid values instead of warning")
+	fs.StringVar(&opts.since, "since", "",
+		"Only process projects whose inputs changed between this git ref and the working tree")
+}
+
+// newGenerateCmd builds the explicit 'generate' subcommand; it behaves like the root command.
+func newGenerateCmd() *cobra.Command {
+	var opts generateOptions
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate (or check / diff) Dockerfiles for one project or the whole repository",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runGenerate(os.Stdout, os.Stderr, opts)
+		},
+	}
+	addGenerateFlags(cmd.Flags(), &opts)
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "check")
+	registerCompletions(cmd)
+	cmd.Example = `  dockerfile-gen generate -p ./service
+  dockerfile-gen generate --all
+  dockerfile-gen generate --all --check --exclude 'tools/**'
+  dockerfile-gen generate --all --check --since origin/main`
+	return cmd
+}
+
+// addConfigFlags registers --config, --profile and --template, bound to opts.
+func addConfigFlags(fs *pflag.FlagSet, opts *generateOptions) {
+	fs.StringVar(&opts.configPath, "config", "",
+		"Use this configuration file instead of the "+config.DefaultDockerBuildFileName+" files found from the repository root down to the project")
+	fs.StringVar(&opts.profile, "profile", "",
+		"Apply this profile from the "+config.DefaultDockerBuildFileName+" 'profiles' map (defaults to $"+profileEnvVar+")")
+	fs.StringVar(&opts.template, "template", "",
+		"Render this text/template file instead of the generator's embedded Dockerfile template (overrides the 'template' key)")
+}
+
+// changedSet asks git for the files changed since ref in the repository containing projectPath.
+func changedSet(projectPath, ref string) (map[string]bool, error) {
+	rootPath := findRepositoryRoot(slog.Default(), projectPath)
+	if rootPath == "" {
+		return nil, fmt.Errorf("cannot find repository root")
+	}
+	files, err := changedFilesSince(rootPath, ref)
+	if err != nil {
+		return nil, fmt.Errorf("--since %s: %w", ref, err)
+	}
+	changed := make(map[string]bool, len(files))
+	for _, f := range files {
+		changed[f] = true
+	}
+	return changed, nil
+}
+
+// checkFailed reports whether a check-mode status should fail the run.
+func checkFailed(s projectStatus) bool {
+	return s == statusStale || s == statusMissing
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
+	"log/slog"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 )
 
 // findRepositoryRoot walks upward from startPath (file or directory) until it finds a .git directory
-// or reaches the filesystem root. Returns empty string if no repository root is found.
-func findRepositoryRoot(startPath string) string {
+// or reaches the filesystem root, logging each step to log. Returns empty string if no repository
+// root is found.
+func findRepositoryRoot(log *slog.Logger, startPath string) string {
 	// Resolve to absolute path (ignore error; non-critical) and normalize to a directory
 	if abs, err := filepath.Abs(startPath); err == nil {
 		startPath = abs
@@ -17,20 +23,20 @@ func findRepositoryRoot(startPath string) string {
 	}
 
 	currentPath := startPath
-	Debugf("git: starting repository root search from %s", currentPath)
+	log.Debug(fmt.Sprintf("git: starting repository root search from %s", currentPath))
 	for {
-		Debugf("git: checking for .git in %s", currentPath)
+		log.Debug(fmt.Sprintf("git: checking for .git in %s", currentPath))
 		if hasGitDir(currentPath) {
-			Debugf("git: found .git directory at %s", currentPath)
+			log.Debug(fmt.Sprintf("git: found .git directory at %s", currentPath))
 			return currentPath
 		}
 		if isRootPath(currentPath) {
-			Debugf("git: reached filesystem root at %s without finding .git", currentPath)
+			log.Debug(fmt.Sprintf("git: reached filesystem root at %s without finding .git", currentPath))
 			return ""
 		}
 		parent := filepath.Dir(currentPath)
 		if parent == currentPath { // safety guard (should not happen beyond root check)
-			Debugf("git: parent path same as current (%s); aborting search", currentPath)
+			log.Debug(fmt.Sprintf("git: parent path same as current (%s); aborting search", currentPath))
 			return ""
 		}
 		currentPath = parent
@@ -46,3 +52,40 @@ func hasGitDir(path string) bool {
 func isRootPath(path string) bool {
 	return path == filepath.Dir(path)
 }
+
+// changedFilesSince returns the absolute paths of files that differ between ref and the working tree
+// of the repository at root, including untracked (non-ignored) files. Renames are reported as a
+// deletion plus an addition so both paths are included. Paths are read NUL-separated (-z) so git
+// does not quote non-ASCII or unusual names.
+func changedFilesSince(root, ref string) ([]string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("invalid git ref %q", ref)
+	}
+	diff, err := gitOutput(root, "diff", "--name-only", "--no-renames", "-z", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := gitOutput(root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, name := range strings.Split(diff+"\x00"+untracked, "\x00") {
+		if name != "" {
+			files = append(files, filepath.Join(root, filepath.FromSlash(name)))
+		}
+	}
+	Debugf("git: %d file(s) changed since %s", len(files), ref)
+	return files, nil
+}
+
+func gitOutput(root string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", root}, args...)...) // #nosec G204 - fixed binary, no shell
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"log/slog"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -24,14 +28,14 @@ func TestHasGitDirAndFindRepositoryRoot(t *testing.T) {
 		t.Fatalf("expected hasGitDir false in nested path")
 	}
 
-	root := findRepositoryRoot(deep)
+	root := findRepositoryRoot(slog.Default(), deep)
 	if root != tdir {
 		t.Fatalf("expected repo root %s got %s", tdir, root)
 	}
 
 	// path without any git root -> empty string
 	plain := t.TempDir()
-	if r := findRepositoryRoot(plain); r != "" {
+	if r := findRepositoryRoot(slog.Default(), plain); r != "" {
 		t.Fatalf("expected empty root, got %q", r)
 	}
 }
@@ -45,3 +49,144 @@ func TestIsRootPath(t *testing.T) {
 		t.Fatalf("temp dir should not be root")
 	}
 }
+
+// runGit runs git in dir with a fixed identity, failing the test on error.
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com",
+		"-c", "commit.gpgsign=false"}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// newGitMonorepo creates a real git repository with a Go service and a .NET app referencing a library,
+// all committed.
+func newGitMonorepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	runGit(t, root, "init", "-q")
+	writeFile(t, filepath.Join(root, "services", "api", "go.mod"), sampleGoMod)
+	writeFile(t, filepath.Join(root, "services", "api", "main.go"), "package main\n")
+	writeFile(t, filepath.Join(root, "src", "App", "App.csproj"),
+		`<Project><ItemGroup><ProjectReference Include="../Lib/Lib.csproj" /></ItemGroup></Project>`)
+	writeFile(t, filepath.Join(root, "src", "Lib", "Lib.csproj"), sampleCsproj)
+	runGit(t, root, "add", "-A")
+	runGit(t, root, "commit", "-q", "-m", "initial")
+	return root
+}
+
+func TestChangedFilesSince(t *testing.T) {
+	root := newGitMonorepo(t)
+	writeFile(t, filepath.Join(root, "services", "api", "go.mod"), sampleGoMod+"\n")
+	writeFile(t, filepath.Join(root, "src", "Directory.Build.props"), "<Project />")
+	runGit(t, root, "mv", "src/Lib/Lib.csproj", "src/Lib/Core.csproj")
+
+	files, err := changedFilesSince(root, "HEAD")
+	if err != nil {
+		t.Fatalf("changedFilesSince: %v", err)
+	}
+	for _, rel := range []string{"services/api/go.mod", "src/Directory.Build.props", "src/Lib/Lib.csproj",
+		"src/Lib/Core.csproj"} {
+		if !slices.Contains(files, filepath.Join(root, filepath.FromSlash(rel))) {
+			t.Fatalf("expected %s in %v", rel, files)
+		}
+	}
+	if _, err := changedFilesSince(root, "--output=/tmp/x"); err == nil {
+		t.Fatalf("expected option-like ref to be rejected")
+	}
+	if _, err := changedFilesSince(root, "no-such-ref"); err == nil {
+		t.Fatalf("expected unknown ref to fail")
+	}
+}
+
+func TestChangedFilesSinceNonASCII(t *testing.T) {
+	root := newGitMonorepo(t)
+	writeFile(t, filepath.Join(root, "Sérvice", "go.mod"), sampleGoMod)
+	runGit(t, root, "add", "-A")
+	runGit(t, root, "commit", "-q", "-m", "add service")
+	writeFile(t, filepath.Join(root, "Sérvice", "go.mod"), sampleGoMod+"\n")
+	writeFile(t, filepath.Join(root, "Sérvice", ".dockerbuild"), "base:\n  image: alpine:3.20\n")
+
+	files, err := changedFilesSince(root, "HEAD")
+	if err != nil {
+		t.Fatalf("changedFilesSince: %v", err)
+	}
+	for _, rel := range []string{"Sérvice/go.mod", "Sérvice/.dockerbuild"} {
+		if !slices.Contains(files, filepath.Join(root, filepath.FromSlash(rel))) {
+			t.Fatalf("expected %s unquoted in %v", rel, files)
+		}
+	}
+}
+
+func TestGenerateAllSince(t *testing.T) {
+	root := newGitMonorepo(t)
+	// Source files are not inputs; a transitive reference is.
+	writeFile(t, filepath.Join(root, "services", "api", "main.go"), "package main\n\nfunc main() {}\n")
+	writeFile(t, filepath.Join(root, "src", "Lib", "Lib.csproj"), sampleCsproj+"\n<!-- touched -->\n")
+
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"--all", "-p", root, "--since", "HEAD"})
+	out := captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	if _, err := os.Stat(filepath.Join(root, "src", "App", "Dockerfile")); err != nil {
+		t.Fatalf("App references the changed Lib and should be generated: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "services", "api", "Dockerfile")); err == nil {
+		t.Fatalf("api is not affected and should be skipped")
+	}
+	if !strings.Contains(out, "unaffected") {
+		t.Fatalf("expected unaffected projects in summary, got %q", out)
+	}
+
+	// A project-level .dockerbuild is an input too.
+	runGit(t, root, "add", "-A")
+	runGit(t, root, "commit", "-q", "-m", "generated")
+	writeFile(t, filepath.Join(root, "services", "api", ".dockerbuild"), "base:\n  image: alpine:3.20\n")
+	cmd = newRootCmd()
+	cmd.SetArgs([]string{"-p", filepath.Join(root, "services", "api"), "--since", "HEAD"})
+	captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	if _, err := os.Stat(filepath.Join(root, "services", "api", "Dockerfile")); err != nil {
+		t.Fatalf("new .dockerbuild should make api affected: %v", err)
+	}
+}
+
+func TestCheckSinceDeletedContextFile(t *testing.T) {
+	root := newGitMonorepo(t)
+	props := filepath.Join(root, "src", "Directory.Build.props")
+	writeFile(t, props, "<Project />")
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"--all", "-p", root})
+	captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	runGit(t, root, "add", "-A")
+	runGit(t, root, "commit", "-q", "-m", "generated")
+
+	// The deleted file is no longer an input, but App's Dockerfile still copies it.
+	if err := os.Remove(props); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	cmd = newRootCmd()
+	cmd.SetArgs([]string{"--all", "-p", root, "--check", "--since", "HEAD"})
+	var err error
+	out := captureStdout(t, func() { err = cmd.Execute() })
+	if err == nil {
+		t.Fatalf("expected --check --since to report App as stale:\n%s", out)
+	}
+	if !strings.Contains(out, "STALE") || !strings.Contains(out, "unaffected") {
+		t.Fatalf("expected App to be stale and the Go service unaffected:\n%s", out)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
+)
+
+// newInitCmd builds the 'init' subcommand that scaffolds a .dockerbuild next to the project.
+func newInitCmd() *cobra.Command {
+	var opts generateOptions
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Write a commented " + config.DefaultDockerBuildFileName + " prefilled from the detected project",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runInit(os.Stdout, opts, force)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVarP(&opts.projectPath, "path", "p", ".",
+		"Path to the project (directory, .csproj, or go.mod). Defaults to current directory.")
+	addLanguageFlag(f, &opts.language)
+	f.BoolVar(&force, "force", false, "Overwrite an existing "+config.DefaultDockerBuildFileName)
+	cmd.Example = `  dockerfile-gen init -p ./src/WebApi
+  dockerfile-gen init -p ./service --force`
+	registerCompletions(cmd)
+	return cmd
+}
+
+func runInit(out io.Writer, opts generateOptions, force bool) error {
+	if force {
+		// The file being overwritten may no longer load (for example an unset ${VAR}).
+		dir := opts.projectPath
+		if fi, err := os.Stat(dir); err == nil && !fi.IsDir() {
+			dir = filepath.Dir(dir)
+		}
+		opts.replacedConfig = filepath.Join(dir, config.DefaultDockerBuildFileName)
+	}
+	r := newProjectRunner(out, os.Stderr)
+	p, err := r.resolveProject(opts, opts.projectPath)
+	if err != nil {
+		return err
+	}
+	dest := filepath.Join(p.dir, config.DefaultDockerBuildFileName)
+	existing, err := config.Find(p.dir)
+	if err != nil {
+		return err
+	}
+	switch {
+	case existing == "":
+	case !force:
+		return fmt.Errorf("%s already exists; use --force to overwrite", existing)
+	case existing != dest:
+		return fmt.Errorf("%s already exists; remove it before writing %s", existing, dest)
+	}
+
+	cfg := config.Config{Language: p.language}
+	if s, ok := p.gen.(generator.ConfigSuggester); ok {
+		cfg = s.SuggestConfig(p.data)
+	}
+	Debugf("init config suggestion: %+v", cfg)
+
+	var b bytes.Buffer
+	if err := config.Scaffold(&b, cfg); err != nil {
+		return fmt.Errorf("error rendering %s: %w", config.DefaultDockerBuildFileName, err)
+	}
+	if err := os.WriteFile(dest, b.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("error writing %s: %w", dest, err)
+	}
+	_, _ = fmt.Fprintf(out, "Created %s (%s)\n", dest, p.language)
+	return nil
+}
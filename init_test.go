@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+func TestInitDotnet(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+	proj := filepath.Join(root, "src", "App")
+	writeFile(t, filepath.Join(proj, "App.csproj"), strings.Replace(sampleCsproj, "net9.0", "net8.0", 1))
+
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"init", "-p", proj})
+	out := captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("init: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Created") {
+		t.Fatalf("expected created message, got %q", out)
+	}
+	cfg, err := config.Load(filepath.Join(proj, config.DefaultDockerBuildFileName))
+	if err != nil {
+		t.Fatalf("load scaffold: %v", err)
+	}
+	if cfg.Language != "dotnet" || cfg.Dotnet.SdkVersion != "8.0" {
+		t.Fatalf("unexpected language/sdk-version: %+v", cfg)
+	}
+	if !strings.Contains(cfg.Base.Image, "dotnet/aspnet") || !strings.Contains(cfg.BaseBuild.Image, "dotnet/sdk") {
+		t.Fatalf("expected default images prefilled, got %+v", cfg)
+	}
+
+	cmd = newRootCmd()
+	cmd.SetArgs([]string{"init", "-p", proj})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected refusal to overwrite, got %v", err)
+	}
+	cmd = newRootCmd()
+	cmd.SetArgs([]string{"init", "-p", proj, "--force"})
+	captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("init --force: %v", err)
+		}
+	})
+}
+
+func TestInitGo(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(dir, "go.mod"), sampleGoMod)
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"init", "-p", dir})
+	captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("init: %v", err)
+		}
+	})
+	data, err := os.ReadFile(filepath.Join(dir, config.DefaultDockerBuildFileName)) // #nosec G304 - test file
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if strings.Contains(string(data), "sdk-version") {
+		t.Fatalf("go scaffold should not contain dotnet section:\n%s", data)
+	}
+	if !strings.Contains(string(data), `language: "go"`) {
+		t.Fatalf("expected go language in scaffold:\n%s", data)
+	}
+}
+
+func TestInitForceReplacesUnloadableConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(dir, "go.mod"), sampleGoMod)
+	dest := filepath.Join(dir, config.DefaultDockerBuildFileName)
+	writeFile(t, dest, "base-build:\n  image: golang:${DG_TEST_GO_VERSION}-alpine\n")
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"init", "-p", dir, "--force"})
+	captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("init --force: %v", err)
+		}
+	})
+	if _, err := config.Load(dest); err != nil {
+		t.Fatalf("expected a loadable scaffold: %v", err)
+	}
+}
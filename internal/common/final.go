@@ -0,0 +1,54 @@
+// revive:disable:var-naming - package name 'common' is intentional for shared types used by multiple packages.
+package common
+
+import (
+	"maps"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+// FinalDefaults are the generator defaults of the final stage, used where the configuration
+// leaves a setting empty.
+type FinalDefaults struct {
+	Workdir    string            // WORKDIR
+	Entrypoint []string          // ENTRYPOINT
+	Ports      []string          // EXPOSE, unless final.ports is set (even to an empty list)
+	Env        map[string]string // ENV; final.env entries override or extend it
+	ProbePath  string            // path of the inferred HTTP health check, "" for none
+}
+
+// ApplyFinalDefaults returns final with d applied. The HTTP probe is only inferred when
+// final.healthcheck.command is not set.
+func ApplyFinalDefaults(final config.FinalConfig, d FinalDefaults) config.FinalConfig {
+	if final.Workdir == "" {
+		final.Workdir = d.Workdir
+	}
+	if len(final.Entrypoint) == 0 {
+		final.Entrypoint = d.Entrypoint
+	}
+	if final.Ports == nil {
+		final.Ports = d.Ports
+	}
+	if d.Env != nil {
+		env := maps.Clone(d.Env)
+		maps.Copy(env, final.Env)
+		final.Env = env
+	}
+	if final.Healthcheck.Command == "" && d.ProbePath != "" {
+		final.Healthcheck = HTTPProbe(final.Healthcheck, final.Ports, d.ProbePath)
+	}
+	return final
+}
+
+// FinalWarnings returns the ProbeWarning of final's health check for the runtime image of base,
+// defaultImage when base does not set one.
+func FinalWarnings(final config.FinalConfig, base config.ImageConfig, defaultImage string) []string {
+	image := base.Image
+	if image == "" {
+		image = defaultImage
+	}
+	if w := ProbeWarning(final.Healthcheck.Command, image, base.Packages); w != "" {
+		return []string{w}
+	}
+	return nil
+}
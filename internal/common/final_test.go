@@ -0,0 +1,58 @@
+// revive:disable:var-naming - package name 'common' is intentional for shared types used by multiple packages.
+package common
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+func TestApplyFinalDefaults(t *testing.T) {
+	defaults := FinalDefaults{
+		Workdir:    "/app",
+		Entrypoint: []string{"./app"},
+		Ports:      []string{"8080"},
+		Env:        map[string]string{"LANG": "en_US.UTF-8", "TZ": "UTC"},
+		ProbePath:  "/health",
+	}
+	got := ApplyFinalDefaults(config.FinalConfig{}, defaults)
+	if got.Workdir != "/app" || !reflect.DeepEqual(got.Entrypoint, []string{"./app"}) ||
+		!reflect.DeepEqual(got.Ports, []string{"8080"}) || !reflect.DeepEqual(got.Env, defaults.Env) {
+		t.Fatalf("defaults not applied: %+v", got)
+	}
+	if !strings.Contains(got.Healthcheck.Command, "http://localhost:8080/health") {
+		t.Fatalf("expected inferred probe, got %q", got.Healthcheck.Command)
+	}
+
+	got = ApplyFinalDefaults(config.FinalConfig{
+		Workdir:     "/srv",
+		Ports:       []string{},
+		Env:         map[string]string{"LANG": "C.UTF-8"},
+		Healthcheck: config.HealthcheckConfig{Command: HealthcheckNone},
+	}, defaults)
+	if got.Workdir != "/srv" || len(got.Ports) != 0 || got.Healthcheck.Command != HealthcheckNone {
+		t.Fatalf("configured values must win: %+v", got)
+	}
+	if want := map[string]string{"LANG": "C.UTF-8", "TZ": "UTC"}; !reflect.DeepEqual(got.Env, want) {
+		t.Fatalf("env = %v, want %v", got.Env, want)
+	}
+	if defaults.Env["LANG"] != "en_US.UTF-8" {
+		t.Fatalf("defaults must not be modified: %v", defaults.Env)
+	}
+}
+
+func TestFinalWarnings(t *testing.T) {
+	final := config.FinalConfig{Healthcheck: config.HealthcheckConfig{Command: "curl -f http://localhost/"}}
+	if got := FinalWarnings(final, config.ImageConfig{}, "alpine:3.20"); len(got) != 1 {
+		t.Fatalf("expected a warning for curl on the default image, got %q", got)
+	}
+	if got := FinalWarnings(final, config.ImageConfig{Packages: []string{"curl"}}, "alpine:3.20"); got != nil {
+		t.Fatalf("expected no warning with curl installed, got %q", got)
+	}
+	final.Healthcheck.Command = "wget --spider http://localhost/"
+	if got := FinalWarnings(final, config.ImageConfig{Image: "gcr.io/distroless/static"}, "alpine:3.20"); len(got) != 1 {
+		t.Fatalf("expected a warning for wget on the configured image, got %q", got)
+	}
+}
@@ -0,0 +1,80 @@
+// revive:disable:var-naming - package name 'common' is intentional for shared types used by multiple packages.
+package common
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+// HealthcheckNone is the command that disables the health check (HEALTHCHECK NONE).
+const HealthcheckNone = "NONE"
+
+// HealthcheckInstruction renders hc as a shell-form HEALTHCHECK instruction, or "" when it has no
+// command. Only the options that are set are written, so Docker's defaults apply to the others.
+func HealthcheckInstruction(hc config.HealthcheckConfig) string {
+	switch hc.Command {
+	case "":
+		return ""
+	case HealthcheckNone:
+		return "HEALTHCHECK NONE"
+	}
+	var b strings.Builder
+	b.WriteString("HEALTHCHECK")
+	for _, opt := range [][2]string{
+		{"interval", hc.Interval}, {"timeout", hc.Timeout}, {"start-period", hc.StartPeriod}, {"retries", hc.Retries},
+	} {
+		if opt[1] != "" {
+			fmt.Fprintf(&b, " --%s=%s", opt[0], opt[1])
+		}
+	}
+	b.WriteString(" \\\n    CMD ")
+	b.WriteString(hc.Command)
+	return b.String()
+}
+
+// HTTPProbe returns a health check polling path on the first TCP port of ports (8080 when there is
+// none) with busybox-compatible wget. Options already set in hc are kept.
+func HTTPProbe(hc config.HealthcheckConfig, ports []string, path string) config.HealthcheckConfig {
+	port := "8080"
+	for _, p := range ports {
+		if number, proto, _ := strings.Cut(p, "/"); proto == "" || proto == "tcp" {
+			port = number
+			break
+		}
+	}
+	hc.Command = fmt.Sprintf("wget --no-verbose --tries=1 --spider http://localhost:%s%s || exit 1", port, path)
+	setDefault := func(v *string, def string) {
+		if *v == "" {
+			*v = def
+		}
+	}
+	setDefault(&hc.Interval, "30s")
+	setDefault(&hc.Timeout, "5s")
+	setDefault(&hc.StartPeriod, "10s")
+	setDefault(&hc.Retries, "3")
+	return hc
+}
+
+// ProbeWarning reports when the program run by a health check command (wget or curl) is unlikely
+// to exist in the runtime image: busybox-based images (alpine, busybox) ship wget but not curl,
+// and other images are not assumed to provide either unless packages installs it. It returns ""
+// when there is nothing to report.
+func ProbeWarning(command, image string, packages []string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 || (fields[0] != "wget" && fields[0] != "curl") {
+		return ""
+	}
+	tool := fields[0]
+	if slices.Contains(packages, tool) {
+		return ""
+	}
+	busybox := strings.Contains(image, "alpine") || strings.Contains(image, "busybox")
+	if tool == "wget" && busybox {
+		return ""
+	}
+	return fmt.Sprintf("the health check runs %s, which the runtime image %s may not provide; "+
+		"add it to base.packages or change final.healthcheck.command", tool, image)
+}
@@ -0,0 +1,63 @@
+// revive:disable:var-naming - package name 'common' is intentional for shared types used by multiple packages.
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+func TestHealthcheckInstruction(t *testing.T) {
+	cases := []struct {
+		hc   config.HealthcheckConfig
+		want string
+	}{
+		{config.HealthcheckConfig{}, ""},
+		{config.HealthcheckConfig{Command: HealthcheckNone, Interval: "10s"}, "HEALTHCHECK NONE"},
+		{config.HealthcheckConfig{Command: "/app/probe"}, "HEALTHCHECK \\\n    CMD /app/probe"},
+		{
+			config.HealthcheckConfig{Command: "curl -f http://localhost/ || exit 1", Interval: "1m", Retries: "5"},
+			"HEALTHCHECK --interval=1m --retries=5 \\\n    CMD curl -f http://localhost/ || exit 1",
+		},
+	}
+	for _, c := range cases {
+		if got := HealthcheckInstruction(c.hc); got != c.want {
+			t.Errorf("HealthcheckInstruction(%+v) = %q, want %q", c.hc, got, c.want)
+		}
+	}
+}
+
+func TestHTTPProbe(t *testing.T) {
+	hc := HTTPProbe(config.HealthcheckConfig{Interval: "1m"}, []string{"53/udp", "5000"}, "/health")
+	if hc.Command != "wget --no-verbose --tries=1 --spider http://localhost:5000/health || exit 1" {
+		t.Fatalf("unexpected probe command %q", hc.Command)
+	}
+	if hc.Interval != "1m" || hc.Timeout != "5s" || hc.StartPeriod != "10s" || hc.Retries != "3" {
+		t.Fatalf("expected configured interval and default options, got %+v", hc)
+	}
+	if got := HTTPProbe(config.HealthcheckConfig{}, nil, "/health").Command; !strings.Contains(got, "localhost:8080/health") {
+		t.Fatalf("expected port 8080 without configured ports, got %q", got)
+	}
+}
+
+func TestProbeWarning(t *testing.T) {
+	wget := "wget --spider http://localhost:8080/health || exit 1"
+	cases := []struct {
+		command, image string
+		packages       []string
+		warn           bool
+	}{
+		{wget, "alpine:3.20", nil, false},
+		{"curl -f http://localhost/", "alpine:3.20", nil, true},
+		{"curl -f http://localhost/", "alpine:3.20", []string{"curl"}, false},
+		{wget, "gcr.io/distroless/static", nil, true},
+		{"/app/probe", "scratch", nil, false},
+		{HealthcheckNone, "scratch", nil, false},
+	}
+	for _, c := range cases {
+		if got := ProbeWarning(c.command, c.image, c.packages); (got != "") != c.warn {
+			t.Errorf("ProbeWarning(%q, %q, %v) = %q, want warning %v", c.command, c.image, c.packages, got, c.warn)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// revive:disable:var-naming - package name 'common' is intentional for shared types used by multiple packages.
+package common
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+// FinalInstructions returns the EXPOSE, ENV, LABEL and HEALTHCHECK instructions of a final stage,
+// in that order, each possibly spanning several lines. Keys are sorted so the output is
+// deterministic; empty settings produce no instruction. Generators pass final with their defaults
+// already applied.
+func FinalInstructions(final config.FinalConfig) []string {
+	var out []string
+	if len(final.Ports) > 0 {
+		out = append(out, "EXPOSE "+strings.Join(final.Ports, " "))
+	}
+	if len(final.Env) > 0 {
+		out = append(out, KeyValueInstruction("ENV", final.Env))
+	}
+	if len(final.Labels) > 0 {
+		out = append(out, KeyValueInstruction("LABEL", final.Labels))
+	}
+	if hc := HealthcheckInstruction(final.Healthcheck); hc != "" {
+		out = append(out, hc)
+	}
+	return out
+}
+
+// KeyValueInstruction renders a key=value instruction such as ENV or LABEL with one pair per
+// line. Keys listed in first come first, in that order, and the others follow sorted. Keys and
+// values are quoted when they contain anything but plain characters.
+func KeyValueInstruction(instruction string, values map[string]string, first ...string) string {
+	var keys []string
+	for _, k := range first {
+		if _, ok := values[k]; ok && !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(values)) {
+		if !slices.Contains(first, k) {
+			keys = append(keys, k)
+		}
+	}
+	var b strings.Builder
+	b.WriteString(instruction)
+	for _, k := range keys {
+		b.WriteString(" \\\n    ")
+		b.WriteString(QuoteValue(k))
+		b.WriteByte('=')
+		b.WriteString(QuoteValue(values[k]))
+	}
+	return b.String()
+}
+
+// QuoteValue returns s unchanged when it only holds characters that need no quoting in a
+// Dockerfile key=value pair, and as a double-quoted string otherwise. Backslashes and double
+// quotes are escaped; "$" is kept so values can still reference build args and variables.
+func QuoteValue(s string) string {
+	if s != "" && !strings.ContainsFunc(s, needsQuoting) {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func needsQuoting(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	case strings.ContainsRune("-_.:/@+,%${}", r):
+		return false
+	}
+	return true
+}
+
+// ExecForm renders args as the JSON array of an exec-form instruction such as ENTRYPOINT or CMD,
+// e.g. ["dotnet", "App.dll"], or "" when args is empty. HTML characters are not escaped so
+// arguments stay readable.
+func ExecForm(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		var b strings.Builder
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		_ = enc.Encode(a) // encoding a string cannot fail
+		quoted[i] = strings.TrimSuffix(b.String(), "\n")
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
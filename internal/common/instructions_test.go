@@ -0,0 +1,51 @@
+// revive:disable:var-naming - package name 'common' is intentional for shared types used by multiple packages.
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+func TestFinalInstructions(t *testing.T) {
+	got := FinalInstructions(config.FinalConfig{
+		Ports:  []string{"8080", "53/udp"},
+		Env:    map[string]string{"LANG": "en_US.UTF-8", "GREETING": "hello world", "APP_DIR": "${HOME}/app"},
+		Labels: map[string]string{"org.opencontainers.image.title": `say "hi"`, "empty": ""},
+	})
+	want := []string{
+		"EXPOSE 8080 53/udp",
+		"ENV \\\n    APP_DIR=${HOME}/app \\\n    GREETING=\"hello world\" \\\n    LANG=en_US.UTF-8",
+		"LABEL \\\n    empty=\"\" \\\n    org.opencontainers.image.title=\"say \\\"hi\\\"\"",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("instructions mismatch:\n got %q\nwant %q", got, want)
+	}
+	if got := FinalInstructions(config.FinalConfig{}); got != nil {
+		t.Fatalf("expected no instructions, got %q", got)
+	}
+}
+
+func TestKeyValueInstructionOrder(t *testing.T) {
+	got := KeyValueInstruction("ENV", map[string]string{"B": "2", "A": "1", "LANG": "C", "Z": "26"}, "Z", "LANG", "MISSING")
+	want := "ENV \\\n    Z=26 \\\n    LANG=C \\\n    A=1 \\\n    B=2"
+	if got != want {
+		t.Fatalf("unexpected instruction:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestQuoteValueEscapesBackslash(t *testing.T) {
+	if got := QuoteValue(`C:\app`); got != `"C:\\app"` {
+		t.Fatalf("unexpected quoting %s", got)
+	}
+}
+
+func TestExecForm(t *testing.T) {
+	if got := ExecForm([]string{"/app/run.sh", `--name="a b"`, "<x> & y", `C:\dir`}); got != `["/app/run.sh", "--name=\"a b\"", "<x> & y", "C:\\dir"]` {
+		t.Fatalf("unexpected exec form %s", got)
+	}
+	if got := ExecForm(nil); got != "" {
+		t.Fatalf("expected empty exec form, got %q", got)
+	}
+}
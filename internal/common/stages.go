@@ -0,0 +1,137 @@
+// revive:disable:var-naming - package name 'common' is intentional for shared types used by multiple packages.
+package common
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+// stageReference matches the stage (or image) named by --from=NAME and by the from=NAME option of
+// --mount in an instruction.
+var stageReference = regexp.MustCompile(`(?:--|,)from=([^\s,]+)`)
+
+// ExtraStages checks the extra stages configured in stages and renders them for a template whose
+// own stages before the final one are builtin (in template order) and whose last stage is final.
+// It returns the stages in dependency order, each a "FROM ... AS name" block ending with a newline,
+// and the COPY --from instructions of the final stage.
+//
+// Stage names must differ from the template's. A from key naming a stage must name one built
+// before the referring one; any other from is an image (scratch, alpine, alpine:3.20, ${IMAGE}).
+// A --from reference in an instruction must name such a stage too, or be an image reference,
+// recognized by a tag, digest, registry or variable, so that a misspelled stage name is reported.
+// The final stage cannot be referenced.
+func ExtraStages(stages map[string]config.Stage, builtin []string, final string) (blocks, copies []string, err error) {
+	if len(stages) == 0 {
+		return nil, nil, nil
+	}
+	names := slices.Sorted(maps.Keys(stages))
+	deps := make(map[string][]string, len(stages))
+	for _, name := range names {
+		if slices.Contains(builtin, name) || name == final {
+			return nil, nil, fmt.Errorf("stage '%s' is already defined by the template (stages: %s)",
+				name, strings.Join(append(slices.Clone(builtin), final), ", "))
+		}
+		s := stages[name]
+		if s.From == "" {
+			return nil, nil, fmt.Errorf("stage '%s' has no from (a stage or an image)", name)
+		}
+		var refs []string
+		if s.From == final || slices.Contains(builtin, s.From) || hasStage(stages, s.From) {
+			refs = append(refs, s.From)
+		}
+		for _, instr := range s.Instructions {
+			for _, m := range stageReference.FindAllStringSubmatch(instr, -1) {
+				refs = append(refs, m[1])
+			}
+		}
+		for _, ref := range refs {
+			switch {
+			case ref == name:
+				return nil, nil, fmt.Errorf("stage '%s' cannot reference itself", name)
+			case ref == final:
+				return nil, nil, fmt.Errorf("stage '%s' cannot reference the %s stage, which is built after it", name, ref)
+			case slices.Contains(builtin, ref) || isImageReference(ref):
+			case hasStage(stages, ref):
+				if !slices.Contains(deps[name], ref) {
+					deps[name] = append(deps[name], ref)
+				}
+			default:
+				return nil, nil, fmt.Errorf("stage '%s' references unknown stage '%s' (stages: %s); "+
+					"images need a tag, digest or registry, e.g. %s:latest",
+					name, ref, strings.Join(append(slices.Clone(builtin), names...), ", "), ref)
+			}
+		}
+	}
+
+	ordered, err := orderStages(names, deps)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, name := range ordered {
+		s := stages[name]
+		var b strings.Builder
+		_, _ = fmt.Fprintf(&b, "FROM %s AS %s\n", s.From, name)
+		for _, instr := range s.Instructions {
+			b.WriteString(strings.TrimRight(instr, "\n"))
+			b.WriteByte('\n')
+		}
+		blocks = append(blocks, b.String())
+		for _, src := range slices.Sorted(maps.Keys(s.CopyIntoFinal)) {
+			copies = append(copies, copyFrom(name, src, s.CopyIntoFinal[src]))
+		}
+	}
+	return blocks, copies, nil
+}
+
+func hasStage(stages map[string]config.Stage, name string) bool {
+	_, ok := stages[name]
+	return ok
+}
+
+// isImageReference reports whether a --from reference can only be an image: stage names have no
+// tag, digest, registry or variable.
+func isImageReference(ref string) bool {
+	return strings.ContainsAny(ref, ":/@$")
+}
+
+// orderStages sorts names so that every stage comes after the stages it depends on, by name
+// among independent stages.
+func orderStages(names []string, deps map[string][]string) ([]string, error) {
+	var ordered []string
+	done := make(map[string]bool, len(names))
+	for len(ordered) < len(names) {
+		progressed := false
+		for _, name := range names {
+			if done[name] || slices.ContainsFunc(deps[name], func(d string) bool { return !done[d] }) {
+				continue
+			}
+			ordered = append(ordered, name)
+			done[name] = true
+			progressed = true
+			break // restart so the order only depends on names, not on iteration
+		}
+		if !progressed {
+			var cycle []string
+			for _, name := range names {
+				if !done[name] {
+					cycle = append(cycle, name)
+				}
+			}
+			return nil, fmt.Errorf("stages %s reference each other in a cycle", strings.Join(cycle, ", "))
+		}
+	}
+	return ordered, nil
+}
+
+// copyFrom renders a COPY --from instruction, in JSON form when a path contains whitespace.
+func copyFrom(stage, src, dest string) string {
+	if strings.ContainsFunc(src+dest, func(r rune) bool { return r == ' ' || r == '\t' }) {
+		return fmt.Sprintf("COPY --from=%s %s", stage, ExecForm([]string{src, dest}))
+	}
+	return fmt.Sprintf("COPY --from=%s %s %s", stage, src, dest)
+}
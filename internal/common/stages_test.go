@@ -0,0 +1,88 @@
+// revive:disable:var-naming - package name 'common' is intentional for shared types used by multiple packages.
+package common
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+func TestExtraStagesOrderAndRender(t *testing.T) {
+	stages := map[string]config.Stage{
+		"migrations": {From: "build", Instructions: []string{
+			"COPY --from=tools /usr/bin/curl /usr/bin/curl",
+			"RUN --mount=type=bind,from=assets,target=/assets go run ./cmd/migrate",
+		}},
+		"tools": {From: "alpine:3.20", Instructions: []string{"RUN apk add --no-cache curl"},
+			CopyIntoFinal: map[string]string{"/usr/bin/curl": "/usr/local/bin/curl", "/opt/my tool": "/opt/tool"}},
+		"assets": {From: "${ASSETS_IMAGE}"},
+	}
+	blocks, copies, err := ExtraStages(stages, []string{"build"}, "final")
+	if err != nil {
+		t.Fatalf("extra stages: %v", err)
+	}
+	wantBlocks := []string{
+		"FROM ${ASSETS_IMAGE} AS assets\n",
+		"FROM alpine:3.20 AS tools\nRUN apk add --no-cache curl\n",
+		"FROM build AS migrations\nCOPY --from=tools /usr/bin/curl /usr/bin/curl\n" +
+			"RUN --mount=type=bind,from=assets,target=/assets go run ./cmd/migrate\n",
+	}
+	if !reflect.DeepEqual(blocks, wantBlocks) {
+		t.Fatalf("blocks mismatch:\n got %q\nwant %q", blocks, wantBlocks)
+	}
+	wantCopies := []string{
+		`COPY --from=tools ["/opt/my tool", "/opt/tool"]`,
+		"COPY --from=tools /usr/bin/curl /usr/local/bin/curl",
+	}
+	if !reflect.DeepEqual(copies, wantCopies) {
+		t.Fatalf("copies mismatch:\n got %q\nwant %q", copies, wantCopies)
+	}
+	if blocks, copies, err := ExtraStages(nil, []string{"build"}, "final"); blocks != nil || copies != nil || err != nil {
+		t.Fatalf("expected nothing without stages, got %q %q %v", blocks, copies, err)
+	}
+}
+
+func TestExtraStagesUntaggedImages(t *testing.T) {
+	stages := map[string]config.Stage{
+		"empty": {From: "scratch", Instructions: []string{"COPY --from=tools /bin/busybox /busybox"}},
+		"tools": {From: "alpine"},
+	}
+	blocks, _, err := ExtraStages(stages, []string{"build"}, "final")
+	if err != nil {
+		t.Fatalf("extra stages: %v", err)
+	}
+	want := []string{"FROM alpine AS tools\n", "FROM scratch AS empty\nCOPY --from=tools /bin/busybox /busybox\n"}
+	if !reflect.DeepEqual(blocks, want) {
+		t.Fatalf("blocks mismatch:\n got %q\nwant %q", blocks, want)
+	}
+}
+
+func TestExtraStagesErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		stages map[string]config.Stage
+		want   string
+	}{
+		{"builtin name", map[string]config.Stage{"build": {From: "alpine:3.20"}},
+			"stage 'build' is already defined by the template (stages: base, build, publish, final)"},
+		{"final name", map[string]config.Stage{"final": {From: "base"}}, "stage 'final' is already defined"},
+		{"missing from", map[string]config.Stage{"tools": {Instructions: []string{"RUN true"}}}, "stage 'tools' has no from"},
+		{"unknown copy", map[string]config.Stage{"tools": {From: "build", Instructions: []string{"COPY --from=asets /a /a"}}},
+			"stage 'tools' references unknown stage 'asets' (stages: base, build, publish, tools)"},
+		{"final reference", map[string]config.Stage{"tools": {From: "build", Instructions: []string{"COPY --from=final /app /app"}}},
+			"stage 'tools' cannot reference the final stage, which is built after it"},
+		{"self reference", map[string]config.Stage{"tools": {From: "tools"}}, "stage 'tools' cannot reference itself"},
+		{"cycle", map[string]config.Stage{"a": {From: "b"}, "b": {From: "build", Instructions: []string{"COPY --from=a / /"}}, "c": {From: "build"}},
+			"stages a, b reference each other in a cycle"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := ExtraStages(tc.stages, []string{"base", "build", "publish"}, "final")
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 type AdditionalFilePath struct {
 	Path     string
 	RootPath string
+	Reason   string // why the file was included (diagnostics only)
 }
 
 // GetRelativePath returns the file path relative to the repository root.
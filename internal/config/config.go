@@ -2,8 +2,13 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -20,45 +25,253 @@ const (
 	DefaultLanguage = LanguageDotnet
 )
 
-// Config represents the top-level configuration.
+// Config represents the top-level configuration. Several files can be merged into one (see Merge);
+// the `merge` tag selects how a list combines with the one inherited from parent directories.
+// Every field carries a `desc` tag (and optionally `default`) used to generate the JSON Schema.
 type Config struct {
-	Language  string       `yaml:"language"`
-	Dotnet    DotnetConfig `yaml:"dotnet"`
-	Base      ImageConfig  `yaml:"base"`
-	BaseBuild ImageConfig  `yaml:"base-build"`
-	Final     FinalConfig  `yaml:"final"`
+	Language  string       `yaml:"language" json:"language,omitempty" validate:"nonempty" desc:"Generator to use; autodetected from the project when omitted."`
+	Template  string       `yaml:"template" json:"template,omitempty" validate:"nonempty" desc:"text/template file replacing the generator's embedded Dockerfile template, relative to the file that sets it."`
+	Dotnet    DotnetConfig `yaml:"dotnet" json:"dotnet" desc:".NET generator settings."`
+	Base      ImageConfig  `yaml:"base" json:"base" desc:"Runtime (final) stage image and extra apk packages."`
+	BaseBuild ImageConfig  `yaml:"base-build" json:"base-build" desc:"Build stage image and extra apk packages."`
+	Final     FinalConfig  `yaml:"final" json:"final" desc:"Settings of the final runtime stage."`
+	// Hooks fill the named hook points of the Dockerfile template (see HookNames).
+	Hooks map[string]Hook `yaml:"hooks" json:"hooks,omitempty" validate:"keys:hook" desc:"Dockerfile snippets inserted at the named hook points of the template (build-pre-restore, build-post-restore, build-post-build, final-pre-entrypoint, final-extra). Merged per hook with the inherited ones."`
+	// Stages are extra build stages placed before the final stage, keyed by stage name (see Stage).
+	Stages map[string]Stage `yaml:"stages" json:"stages,omitempty" validate:"keys:stagename" desc:"Extra build stages keyed by stage name, placed before the final stage in dependency order (e.g. a migrations stage from build). Merged per stage with the inherited ones."`
+	// Profiles are named overrides of the sections above, selected with --profile (see ApplyProfile).
+	Profiles map[string]Profile `yaml:"profiles" json:"profiles,omitempty" desc:"Named overrides of the template key and the dotnet, base, base-build and final sections, selected with --profile or DOCKERFILE_GEN_PROFILE."`
+}
+
+// Profile overrides top-level sections when selected. Unset keys keep the top-level value; lists
+// follow the same append/replace rules as the cascade.
+type Profile struct {
+	Template  string       `yaml:"template" json:"template,omitempty" validate:"nonempty" desc:"Dockerfile template file for this profile, relative to the file that sets it."`
+	Dotnet    DotnetConfig `yaml:"dotnet" json:"dotnet" desc:".NET generator settings for this profile."`
+	Base      ImageConfig  `yaml:"base" json:"base" desc:"Runtime (final) stage overrides for this profile."`
+	BaseBuild ImageConfig  `yaml:"base-build" json:"base-build" desc:"Build stage overrides for this profile."`
+	Final     FinalConfig  `yaml:"final" json:"final" desc:"Final stage overrides for this profile."`
+}
+
+// HookNames are the hook points of the built-in templates, in template order:
+//   - build-pre-restore: build stage, before dependencies are restored (dotnet restore, go mod download)
+//   - build-post-restore: build stage, after the restore and before the sources are copied
+//   - build-post-build: end of the build stage, after the build
+//   - final-pre-entrypoint: final stage, before ENTRYPOINT
+//   - final-extra: end of the final stage, after ENTRYPOINT and CMD
+var HookNames = []string{"build-pre-restore", "build-post-restore", "build-post-build", "final-pre-entrypoint", "final-extra"}
+
+// Hook is the content of a hook point: a raw snippet, a template file rendered with the template
+// data, or both (the snippet comes first).
+type Hook struct {
+	Snippet string `yaml:"snippet" json:"snippet,omitempty" expand:"-" desc:"Raw Dockerfile lines inserted at the hook point as is: ${...} is not expanded from the environment."`
+	File    string `yaml:"file" json:"file,omitempty" validate:"nonempty" desc:"text/template file rendered with the Dockerfile template data and inserted after the snippet, relative to the file that sets it."`
+}
+
+// Stage is an extra stage of the generated Dockerfile. From names a stage of the template (other
+// than final), another extra stage or an image; instructions may reference stages with --from.
+type Stage struct {
+	From          string            `yaml:"from" json:"from,omitempty" validate:"nonempty,singleline" desc:"Stage (of the template, e.g. build, or another extra stage) or image the stage starts from. Anything that is not a stage name is an image, e.g. scratch or alpine:3.20."`
+	Instructions  []string          `yaml:"instructions" json:"instructions,omitempty" validate:"nonempty" merge:"replace" expand:"-" desc:"Dockerfile instructions of the stage, e.g. RUN dotnet ef migrations bundle, inserted as is: ${...} is not expanded from the environment. Replaces the inherited list."`
+	CopyIntoFinal map[string]string `yaml:"copy-into-final" json:"copy-into-final,omitempty" validate:"nonempty,singleline" desc:"Files copied from this stage into the final stage (COPY --from), as source path in the stage: destination in the final stage. Merged per source with the inherited ones."`
+}
+
+// ProfileNames returns the names of the profiles defined in c, sorted.
+func (c Config) ProfileNames() []string {
+	return slices.Sorted(maps.Keys(c.Profiles))
 }
 
 // DotnetConfig represents .NET-specific configuration.
 type DotnetConfig struct {
-	SdkVersion string `yaml:"sdk-version"`
+	SdkVersion string `yaml:"sdk-version" json:"sdk-version,omitempty" validate:"version" default:"9.0" desc:"Target .NET version (TARGET_DOTNET_VERSION build arg) as major.minor; detected from <TargetFramework> by init."`
 }
 
 // FinalConfig represents configuration applied to the final runtime image.
 type FinalConfig struct {
-	Run []string `yaml:"run"`
+	Run        []string          `yaml:"run" json:"run,omitempty" validate:"nonempty" merge:"replace" desc:"Commands run in the final stage, one RUN instruction each. Replaces the inherited list."`
+	Ports      []string          `yaml:"ports" json:"ports,omitempty" validate:"port" merge:"replace" desc:"Ports exposed by the final stage (EXPOSE), as number or number/protocol. Replaces the inherited list and the generator default."`
+	Env        map[string]string `yaml:"env" json:"env,omitempty" validate:"keys:envname,singleline" desc:"Environment variables set in the final stage (ENV). Merged per variable with the inherited ones and the generator defaults."`
+	Labels     map[string]string `yaml:"labels" json:"labels,omitempty" validate:"singleline" desc:"Image labels set in the final stage (LABEL), e.g. org.opencontainers.image.source. Merged per label with the inherited ones."`
+	Workdir    string            `yaml:"workdir" json:"workdir,omitempty" validate:"nonempty,singleline" desc:"Working directory of the final stage (WORKDIR); the application is copied there. Defaults to /app."`
+	Entrypoint []string          `yaml:"entrypoint" json:"entrypoint,omitempty" validate:"items,nonempty" merge:"replace" desc:"ENTRYPOINT in exec form, e.g. [\"/app/run.sh\"]. Replaces the generator default (dotnet <project>.dll, ./app)."`
+	Cmd        []string          `yaml:"cmd" json:"cmd,omitempty" merge:"replace" desc:"Default arguments of the entrypoint (CMD in exec form)."`
+	// Healthcheck is rendered as a HEALTHCHECK instruction; generators may infer a probe when unset.
+	Healthcheck HealthcheckConfig `yaml:"healthcheck" json:"healthcheck" desc:"HEALTHCHECK of the final stage. Generators infer an HTTP probe when the project references a health checks library."`
+}
+
+// HealthcheckConfig configures the HEALTHCHECK instruction. Durations use the Docker syntax
+// (30s, 1m30s); unset options keep the Docker defaults, or the defaults of an inferred probe.
+type HealthcheckConfig struct {
+	Command     string `yaml:"command" json:"command,omitempty" validate:"singleline" desc:"Probe run by the shell (HEALTHCHECK CMD). NONE disables the health check, including an inferred one."`
+	Interval    string `yaml:"interval" json:"interval,omitempty" validate:"duration" desc:"Time between probes (--interval), e.g. 30s."`
+	Timeout     string `yaml:"timeout" json:"timeout,omitempty" validate:"duration" desc:"Time after which a probe is considered failed (--timeout), e.g. 5s."`
+	StartPeriod string `yaml:"start-period" json:"start-period,omitempty" validate:"duration" desc:"Initialization time during which failures are not counted (--start-period), e.g. 10s."`
+	Retries     string `yaml:"retries" json:"retries,omitempty" validate:"count" desc:"Consecutive failures needed to report the container unhealthy (--retries)."`
 }
 
 // ImageConfig describes an image reference and optional extra packages layer.
 type ImageConfig struct {
-	Image    string   `yaml:"image"`
-	Packages []string `yaml:"packages"`
+	Image    string   `yaml:"image" json:"image,omitempty" validate:"nonempty" desc:"Image reference; the generator default is used when omitted."`
+	Packages []string `yaml:"packages" json:"packages,omitempty" validate:"nonempty" merge:"append" desc:"apk packages installed in the stage. Appended to the inherited list."`
 }
 
-// Load reads and unmarshals a configuration file from disk.
+// Load reads a configuration file from disk and fails on any problem, including the schema
+// diagnostics reported by Parse.
 func Load(path string) (Config, error) {
+	cfg, diags, err := Parse(path)
+	if err != nil {
+		return Config{}, err
+	}
+	if len(diags) > 0 {
+		return Config{}, &ValidationError{Diagnostics: diags}
+	}
+	return cfg, nil
+}
+
+// Parse reads a configuration file from disk, expands environment references in its values (see
+// Expand) and validates it against the schema. The error is reserved for unreadable or
+// syntactically invalid files, failed expansions and invalid stages (a *ValidationError when the
+// position is known; see ErrUnsetVariable, ErrInvalidReference and ErrInvalidStages); schema
+// problems are returned as diagnostics alongside the config decoded from every valid entry (the
+// entries they report are left out), so callers choose whether they are fatal.
+func Parse(path string) (Config, []Diagnostic, error) {
+	l, diags, err := ParseLayer(path)
+	return l.Config, diags, err
+}
+
+// ParseLayer is Parse for a file that takes part in a cascade (see Merge).
+func ParseLayer(path string) (Layer, []Diagnostic, error) {
 	clean := filepath.Clean(path)
 	// #nosec G304 - user supplied path is intentionally read; cleaned above.
 	data, err := os.ReadFile(clean)
 	if err != nil {
-		return Config{}, err
+		return Layer{}, nil, err
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return Config{}, err
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		if d, ok := syntaxDiagnostic(path, err); ok {
+			return Layer{}, nil, &ValidationError{Diagnostics: []Diagnostic{d}}
+		}
+		return Layer{}, nil, fmt.Errorf("%s: %w", path, err)
 	}
-	return cfg, nil
+	if diags := interpolate(path, &doc); len(diags) > 0 {
+		return Layer{}, nil, &ValidationError{Diagnostics: diags}
+	}
+	diags, invalid := validate(path, &doc)
+
+	l := Layer{Path: path}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		l.root = doc.Content[0]
+	}
+	if diags := checkStages(path, l.root); len(diags) > 0 {
+		return Layer{}, nil, &ValidationError{Diagnostics: diags}
+	}
+	prune(&doc, invalid)
+	if err := doc.Decode(&l.Config); err != nil {
+		// Invalid entries are pruned above; this only guards against type errors Validate missed.
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) || len(diags) == 0 {
+			return Layer{}, nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	resolvePaths(&l.Config, filepath.Dir(path))
+	return l, diags, nil
+}
+
+// ErrInvalidStages is matched (errors.Is) by the error Parse returns when the stages section has a
+// duplicate stage name or cannot be decoded.
+var ErrInvalidStages = errors.New("invalid stages")
+
+// checkStages reports duplicate stage names and decoding failures of the stages section of root.
+// These are errors rather than diagnostics: the decoder drops the whole stages map when one entry
+// fails, which would silently generate without any extra stage.
+func checkStages(file string, root *yaml.Node) []Diagnostic {
+	if root == nil || root.Kind != yaml.MappingNode {
+		return nil
+	}
+	var stages *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "stages" {
+			stages = root.Content[i+1]
+		}
+	}
+	if stages == nil {
+		return nil
+	}
+	var diags []Diagnostic
+	if stages.Kind == yaml.MappingNode {
+		first := make(map[string]int, len(stages.Content)/2)
+		for i := 0; i+1 < len(stages.Content); i += 2 {
+			k := stages.Content[i]
+			if line, ok := first[k.Value]; ok {
+				diags = append(diags, Diagnostic{File: file, Line: k.Line, Column: k.Column, Err: ErrInvalidStages,
+					Message: fmt.Sprintf("duplicate stage '%s' (first defined on line %d)", k.Value, line)})
+				continue
+			}
+			first[k.Value] = k.Line
+		}
+	}
+	if len(diags) > 0 {
+		return diags
+	}
+	var decoded map[string]Stage
+	if err := stages.Decode(&decoded); err != nil {
+		msg := err.Error()
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			msg = strings.Join(typeErr.Errors, "; ")
+		}
+		diags = append(diags, Diagnostic{File: file, Line: stages.Line, Column: stages.Column, Err: ErrInvalidStages,
+			Message: "invalid stages: " + msg})
+	}
+	return diags
+}
+
+// resolvePaths makes the file paths of cfg (template and hook files) relative to dir, the
+// directory of the file that sets them, unless they are absolute.
+func resolvePaths(cfg *Config, dir string) {
+	resolve := func(p *string) {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+	resolve(&cfg.Template)
+	for name, h := range cfg.Hooks {
+		resolve(&h.File)
+		cfg.Hooks[name] = h
+	}
+	for name, p := range cfg.Profiles {
+		resolve(&p.Template)
+		cfg.Profiles[name] = p
+	}
+}
+
+// FileNames are the accepted configuration file names, in lookup order. All of them are parsed as
+// YAML, which JSON is a subset of.
+var FileNames = []string{
+	DefaultDockerBuildFileName,
+	DefaultDockerBuildFileName + ".yaml",
+	DefaultDockerBuildFileName + ".yml",
+	DefaultDockerBuildFileName + ".json",
+}
+
+// Find returns the configuration file in dir, or "" when there is none. Having more than one of
+// FileNames in the same directory is an error, since only one of them would be read.
+func Find(dir string) (string, error) {
+	var found []string
+	for _, name := range FileNames {
+		if fi, err := os.Stat(filepath.Join(dir, name)); err == nil && !fi.IsDir() {
+			found = append(found, name)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", nil
+	case 1:
+		return filepath.Join(dir, found[0]), nil
+	}
+	return "", fmt.Errorf("multiple configuration files in %s (%s); keep only one", dir, strings.Join(found, ", "))
 }
 
 // Default returns a Config with no language preset so autodetection can occur
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -34,3 +37,487 @@ func TestDefaultConfig(t *testing.T) {
 		t.Fatalf("expected default language empty got %s", cfg.Language)
 	}
 }
+
+func TestScaffoldRoundTrip(t *testing.T) {
+	want := Config{
+		Language:  LanguageDotnet,
+		Dotnet:    DotnetConfig{SdkVersion: "8.0"},
+		Base:      ImageConfig{Image: "mcr.microsoft.com/dotnet/aspnet:8.0-alpine"},
+		BaseBuild: ImageConfig{Image: "mcr.microsoft.com/dotnet/sdk:${TARGET_DOTNET_VERSION}-alpine"},
+	}
+	file := filepath.Join(t.TempDir(), DefaultDockerBuildFileName)
+	var b strings.Builder
+	if err := Scaffold(&b, want); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	if !strings.Contains(b.String(), "# final:") {
+		t.Fatalf("expected commented optional sections, got:\n%s", b.String())
+	}
+	if err := os.WriteFile(file, []byte(b.String()), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	got, err := Load(file)
+	if err != nil {
+		t.Fatalf("load scaffold: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("round trip mismatch:\nwant %+v\ngot  %+v", want, got)
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	file := filepath.Join(t.TempDir(), DefaultDockerBuildFileName)
+	content := "language: go\n" +
+		"base_build:\n" +
+		"  image: foo\n" +
+		"base:\n" +
+		"  image: \"\"\n" +
+		"  packages: curl\n" +
+		"dotnet:\n" +
+		"  sdk-version: latest\n" +
+		"final:\n" +
+		"  run: [ls, {a: b}]\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cfg, diags, err := Parse(file)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []string{
+		file + ":2:1: unknown key 'base_build' (did you mean 'base-build'?)",
+		file + ":5:10: 'base.image' must not be empty",
+		file + ":6:13: 'base.packages' must be a list, got \"curl\"",
+		file + ":8:16: 'dotnet.sdk-version' must be a major.minor version such as 9.0, got \"latest\"",
+		file + ":10:13: 'final.run[1]' must be a string, got a mapping",
+	}
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("diagnostics mismatch:\n got %q\nwant %q", got, want)
+	}
+	if cfg.Language != LanguageGo {
+		t.Fatalf("valid keys should still be decoded, got %+v", cfg)
+	}
+	if _, err := Load(file); err == nil {
+		t.Fatalf("Load should fail on diagnostics")
+	}
+}
+
+func TestParseIgnoresInvalidEntries(t *testing.T) {
+	file := filepath.Join(t.TempDir(), DefaultDockerBuildFileName)
+	content := "dotnet:\n  sdk-version: abc\nbase:\n  image: alpine:3.20\n  packages: [\"\"]\n" +
+		"final:\n  ports: [\"8080\", \"99999\"]\n  env: {GOOD: \"1\", 1BAD: \"2\"}\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cfg, diags, err := Parse(file)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(diags) != 4 {
+		t.Fatalf("expected 4 diagnostics, got %v", diags)
+	}
+	want := Config{
+		Base:  ImageConfig{Image: "alpine:3.20"},
+		Final: FinalConfig{Ports: []string{"8080"}, Env: map[string]string{"GOOD": "1"}},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("invalid entries should be left out:\n got %+v\nwant %+v", cfg, want)
+	}
+}
+
+func TestParseSyntaxErrorHasLine(t *testing.T) {
+	file := filepath.Join(t.TempDir(), DefaultDockerBuildFileName)
+	if err := os.WriteFile(file, []byte("language: go\nbase: [unclosed\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_, _, err := Parse(file)
+	var ve *ValidationError
+	if !errors.As(err, &ve) || !strings.HasPrefix(err.Error(), file+":") {
+		t.Fatalf("expected positioned syntax error, got %v", err)
+	}
+}
+
+func TestParseEmptyFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), DefaultDockerBuildFileName)
+	if err := os.WriteFile(file, []byte("# nothing yet\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := Load(file); err != nil {
+		t.Fatalf("empty config should be valid: %v", err)
+	}
+}
+
+func TestMergeCascade(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		return file
+	}
+	rootFile := write("root.yaml", "base:\n  image: alpine:3.20\n  packages: [tzdata]\nbase-build:\n  image: golang:1.23-alpine\nfinal:\n  run: [\"echo root\"]\n  ports: [\"8080\"]\n  env: {A: root, B: root}\n")
+	svcFile := write("svc.yaml", "language: go\nbase:\n  image: alpine:3.21\n  packages: [curl]\nfinal:\n  run: [\"echo svc\"]\n  env: {B: svc}\n")
+
+	var layers []Layer
+	for _, f := range []string{rootFile, svcFile} {
+		l, diags, err := ParseLayer(f)
+		if err != nil || len(diags) > 0 {
+			t.Fatalf("parse %s: %v %v", f, err, diags)
+		}
+		layers = append(layers, l)
+	}
+	cfg, sources := Merge(layers)
+	want := Config{
+		Language:  LanguageGo,
+		Base:      ImageConfig{Image: "alpine:3.21", Packages: []string{"tzdata", "curl"}},
+		BaseBuild: ImageConfig{Image: "golang:1.23-alpine"},
+		Final: FinalConfig{
+			Run: []string{"echo svc"}, Ports: []string{"8080"}, Env: map[string]string{"A": "root", "B": "svc"},
+		},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("merged config mismatch:\n got %+v\nwant %+v", cfg, want)
+	}
+	wantSources := []Source{
+		{Key: "language", Value: "go", Files: []string{svcFile}},
+		{Key: "base.image", Value: "alpine:3.21", Files: []string{svcFile}},
+		{Key: "base.packages", Value: "tzdata, curl", Files: []string{rootFile, svcFile}},
+		{Key: "base-build.image", Value: "golang:1.23-alpine", Files: []string{rootFile}},
+		{Key: "final.run", Value: "echo svc", Files: []string{svcFile}},
+		{Key: "final.ports", Value: "8080", Files: []string{rootFile}},
+		{Key: "final.env.A", Value: "root", Files: []string{rootFile}},
+		{Key: "final.env.B", Value: "svc", Files: []string{svcFile}},
+	}
+	if !reflect.DeepEqual(sources, wantSources) {
+		t.Fatalf("sources mismatch:\n got %+v\nwant %+v", sources, wantSources)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	env := map[string]string{"REGISTRY": "registry.local", "EMPTY": ""}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{in: "alpine:3.20", want: "alpine:3.20"},
+		{in: "${REGISTRY}/app:1", want: "registry.local/app:1"},
+		{in: "${MISSING:-docker.io}/app", want: "docker.io/app"},
+		{in: "${EMPTY:-fallback}", want: "fallback"},
+		{in: "x${EMPTY}y", want: "xy"},
+		{in: "sdk:$${TARGET_DOTNET_VERSION}-alpine", want: "sdk:${TARGET_DOTNET_VERSION}-alpine"},
+		{in: "chown $APP_UID /app", want: "chown $APP_UID /app"},
+		{in: "${MISSING}", wantErr: true},
+		{in: "${REGISTRY", wantErr: true},
+		{in: "${1BAD}", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Expand(tt.in, lookup)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Expand(%q) = %q, %v; want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseInterpolation(t *testing.T) {
+	t.Setenv("DG_TEST_REGISTRY", "registry.local")
+	file := filepath.Join(t.TempDir(), DefaultDockerBuildFileName)
+	content := "base:\n  image: ${DG_TEST_REGISTRY}/alpine:3.20\n  packages: [\"${DG_TEST_PKG:-curl}\"]\n" +
+		"base-build:\n  image: \"sdk:$${TARGET_DOTNET_VERSION}\"\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cfg, err := Load(file)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Base.Image != "registry.local/alpine:3.20" || cfg.Base.Packages[0] != "curl" ||
+		cfg.BaseBuild.Image != "sdk:${TARGET_DOTNET_VERSION}" {
+		t.Fatalf("unexpected expansion: %+v", cfg)
+	}
+
+	if err := os.WriteFile(file, []byte("base:\n  image: ${DG_TEST_UNSET}/alpine\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_, _, err = Parse(file)
+	if !errors.Is(err, ErrUnsetVariable) ||
+		!strings.HasPrefix(err.Error(), file+":2:10: unset variable DG_TEST_UNSET (write $${DG_TEST_UNSET}") {
+		t.Fatalf("expected positioned unset variable error, got %v", err)
+	}
+
+	if err := os.WriteFile(file, []byte("base:\n  image: ${1BAD}/alpine\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, _, err = Parse(file); !errors.Is(err, ErrInvalidReference) {
+		t.Fatalf("expected invalid reference error, got %v", err)
+	}
+}
+
+func TestParseInterpolationKeepsHookSnippets(t *testing.T) {
+	t.Setenv("DG_TEST_REGISTRY", "registry.local")
+	file := filepath.Join(t.TempDir(), DefaultDockerBuildFileName)
+	content := "base:\n  image: ${DG_TEST_REGISTRY}/alpine:3.20\n" +
+		"hooks:\n  final-extra:\n    snippet: RUN echo ${APP_VERSION} ${DG_TEST_REGISTRY}\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cfg, diags, err := Parse(file)
+	if err != nil || len(diags) > 0 {
+		t.Fatalf("parse: %v %v", err, diags)
+	}
+	if got := cfg.Hooks["final-extra"].Snippet; got != "RUN echo ${APP_VERSION} ${DG_TEST_REGISTRY}" {
+		t.Fatalf("expected the snippet to be kept as is, got %q", got)
+	}
+	if cfg.Base.Image != "registry.local/alpine:3.20" {
+		t.Fatalf("expected other values to be expanded, got %q", cfg.Base.Image)
+	}
+}
+
+func TestParseInterpolationKeepsStageInstructions(t *testing.T) {
+	t.Setenv("DG_TEST_IMAGE", "alpine:3.20")
+	file := filepath.Join(t.TempDir(), DefaultDockerBuildFileName)
+	content := "stages:\n  tools:\n    from: ${DG_TEST_IMAGE}\n" +
+		"    instructions: [\"ARG TOOL_VERSION\", \"RUN install ${TOOL_VERSION}\"]\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cfg, diags, err := Parse(file)
+	if err != nil || len(diags) > 0 {
+		t.Fatalf("parse: %v %v", err, diags)
+	}
+	tools := cfg.Stages["tools"]
+	if tools.From != "alpine:3.20" || tools.Instructions[1] != "RUN install ${TOOL_VERSION}" {
+		t.Fatalf("expected from to be expanded and instructions kept as is, got %+v", tools)
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), DefaultDockerBuildFileName)
+	content := "base:\n  image: alpine:3.20\n  packages: [tzdata]\nfinal:\n  run: [\"echo base\"]\n" +
+		"profiles:\n" +
+		"  debug:\n    base:\n      packages: [curl]\n    final:\n      env: {DEBUG: \"1\"}\n" +
+		"  prod:\n    base:\n      image: alpine:3.21\n    final:\n      run: []\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	l, diags, err := ParseLayer(file)
+	if err != nil || len(diags) > 0 {
+		t.Fatalf("parse: %v %v", err, diags)
+	}
+	cfg, sources := Merge([]Layer{l})
+	if got := cfg.ProfileNames(); !reflect.DeepEqual(got, []string{"debug", "prod"}) {
+		t.Fatalf("unexpected profile names %v", got)
+	}
+
+	debug, debugSources, err := ApplyProfile(cfg, sources, "debug")
+	if err != nil {
+		t.Fatalf("apply debug: %v", err)
+	}
+	if debug.Base.Image != "alpine:3.20" || !reflect.DeepEqual(debug.Base.Packages, []string{"tzdata", "curl"}) ||
+		!reflect.DeepEqual(debug.Final.Run, []string{"echo base"}) {
+		t.Fatalf("unexpected debug config: %+v", debug)
+	}
+	if !reflect.DeepEqual(debug.Final.Env, map[string]string{"DEBUG": "1"}) || cfg.Final.Env != nil {
+		t.Fatalf("unexpected debug env %v (top-level %v)", debug.Final.Env, cfg.Final.Env)
+	}
+	for _, s := range debugSources {
+		if s.Key == "base.packages" && !reflect.DeepEqual(s.Files, []string{file, file + " (profile debug)"}) {
+			t.Fatalf("unexpected base.packages sources %v", s.Files)
+		}
+	}
+	if !reflect.DeepEqual(cfg.Base.Packages, []string{"tzdata"}) {
+		t.Fatalf("applying a profile must not modify the input config: %+v", cfg.Base)
+	}
+
+	prod, _, err := ApplyProfile(cfg, sources, "prod")
+	if err != nil {
+		t.Fatalf("apply prod: %v", err)
+	}
+	if prod.Base.Image != "alpine:3.21" || len(prod.Final.Run) != 0 {
+		t.Fatalf("unexpected prod config: %+v", prod)
+	}
+
+	if _, _, err := ApplyProfile(cfg, sources, "staging"); err == nil ||
+		!strings.Contains(err.Error(), "available: debug, prod") {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+}
+
+func TestValidateProfiles(t *testing.T) {
+	file := filepath.Join(t.TempDir(), DefaultDockerBuildFileName)
+	if err := os.WriteFile(file, []byte("profiles:\n  debug:\n    language: go\n    base:\n      image: \"\"\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_, diags, err := Parse(file)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(diags) != 2 || !strings.Contains(diags[0].Message, "unknown key 'profiles.debug.language'") ||
+		!strings.Contains(diags[1].Message, "'profiles.debug.base.image' must not be empty") {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+}
+
+func TestValidateFinal(t *testing.T) {
+	file := filepath.Join(t.TempDir(), DefaultDockerBuildFileName)
+	content := "final:\n  ports: [\"8080\", \"53/udp\", \"70000\", \"80/sctp\"]\n" +
+		"  env:\n    APP_MODE: prod\n    1BAD: x\n  labels:\n    description: \"line one\\nline two\"\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_, diags, err := Parse(file)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []string{
+		`'final.ports[2]' must be a port number (1-65535) optionally followed by /tcp or /udp, got "70000"`,
+		`'final.ports[3]' must be a port number (1-65535) optionally followed by /tcp or /udp, got "80/sctp"`,
+		`'final.env' key must be a valid environment variable name, got "1BAD"`,
+		`'final.labels.description' must fit on a single line`,
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("expected %d diagnostics, got %v", len(want), diags)
+	}
+	for i, d := range diags {
+		if d.Message != want[i] {
+			t.Errorf("diagnostic %d: got %q, want %q", i, d.Message, want[i])
+		}
+	}
+}
+
+func TestValidateEntrypoint(t *testing.T) {
+	file := filepath.Join(t.TempDir(), DefaultDockerBuildFileName)
+	content := "final:\n  workdir: \"\"\n  entrypoint: []\n  cmd: []\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_, diags, err := Parse(file)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(diags) != 2 || diags[0].Message != "'final.workdir' must not be empty" ||
+		diags[1].Message != "'final.entrypoint' must not be an empty list" {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+}
+
+func TestValidateHooks(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, DefaultDockerBuildFileName)
+	content := "hooks:\n  build-post-build:\n    file: hooks/post.tmpl\n  final-pre-entrypiont:\n    snippet: USER app\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cfg, diags, err := Parse(file)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(diags) != 1 || !strings.HasPrefix(diags[0].Message, "unknown hook 'final-pre-entrypiont' (did you mean 'final-pre-entrypoint'?)") {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+	if got := cfg.Hooks["build-post-build"].File; got != filepath.Join(dir, "hooks", "post.tmpl") {
+		t.Fatalf("expected hook file relative to the config file, got %s", got)
+	}
+}
+
+func TestValidateStages(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, DefaultDockerBuildFileName)
+	content := "stages:\n  tools:\n    from: alpine:3.20\n    copy-into-final:\n      /usr/bin/curl: /usr/local/bin/curl\n" +
+		"  Migrations:\n    from: build\n    instructions: [\"RUN true\"]\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cfg, diags, err := Parse(file)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(diags) != 1 || diags[0].Line != 6 || !strings.HasPrefix(diags[0].Message, "'stages' key must be a stage name") {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+	if got := cfg.Stages["tools"].CopyIntoFinal["/usr/bin/curl"]; got != "/usr/local/bin/curl" {
+		t.Fatalf("expected copy-into-final decoded, got %q", got)
+	}
+}
+
+func TestParseInvalidStagesFails(t *testing.T) {
+	file := filepath.Join(t.TempDir(), DefaultDockerBuildFileName)
+	for content, want := range map[string]string{
+		"stages:\n  tools:\n    from: alpine:3.20\n  tools:\n    from: alpine:3.21\n": file + ":4:3: duplicate stage 'tools' (first defined on line 2)",
+		"stages:\n  tools:\n    from: alpine:3.20\n    instructions: 5\n":             file + ":2:3: invalid stages: line 4: cannot unmarshal",
+	} {
+		if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		_, _, err := Parse(file)
+		if !errors.Is(err, ErrInvalidStages) || !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	if got, err := Find(dir); err != nil || got != "" {
+		t.Fatalf("expected no config file, got %q, %v", got, err)
+	}
+	jsonFile := filepath.Join(dir, DefaultDockerBuildFileName+".json")
+	if err := os.WriteFile(jsonFile, []byte(`{"language": "go", "base": {"packages": ["tzdata"]}}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	got, err := Find(dir)
+	if err != nil || got != jsonFile {
+		t.Fatalf("expected %s, got %q, %v", jsonFile, got, err)
+	}
+	cfg, err := Load(got)
+	if err != nil || cfg.Language != LanguageGo || len(cfg.Base.Packages) != 1 {
+		t.Fatalf("unexpected JSON config %+v, %v", cfg, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, DefaultDockerBuildFileName+".yml"), []byte("language: go\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := Find(dir); err == nil || !strings.Contains(err.Error(), ".dockerbuild.yml, .dockerbuild.json") {
+		t.Fatalf("expected multiple configuration files error, got %v", err)
+	}
+}
+
+// TestSchemaMetadata fails when a config field is added without the metadata the JSON Schema needs.
+func TestSchemaMetadata(t *testing.T) {
+	var walk func(typ reflect.Type, path string)
+	walk = func(typ reflect.Type, path string) {
+		switch typ.Kind() {
+		case reflect.Map, reflect.Slice:
+			walk(typ.Elem(), path)
+		case reflect.Struct:
+			for i := range typ.NumField() {
+				f := typ.Field(i)
+				key := path + "." + f.Name
+				if name, _, _ := strings.Cut(f.Tag.Get("yaml"), ","); name == "" {
+					t.Errorf("%s has no yaml tag", key)
+				}
+				if f.Tag.Get("desc") == "" {
+					t.Errorf("%s has no desc tag (used as the JSON Schema description)", key)
+				}
+				walk(f.Type, key)
+			}
+		}
+	}
+	walk(reflect.TypeFor[Config](), "Config")
+
+	s := Schema([]string{LanguageDotnet, LanguageGo})
+	props := s["properties"].(map[string]any)
+	sdk := props["dotnet"].(map[string]any)["properties"].(map[string]any)["sdk-version"].(map[string]any)
+	if sdk["default"] != "9.0" || sdk["pattern"] == nil {
+		t.Fatalf("expected default and pattern on dotnet.sdk-version, got %v", sdk)
+	}
+	if _, ok := props["profiles"].(map[string]any)["additionalProperties"].(map[string]any); !ok {
+		t.Fatalf("expected profiles to map names to profile schemas")
+	}
+}
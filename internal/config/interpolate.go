@@ -0,0 +1,154 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnsetVariable is matched (errors.Is) by the error Parse returns when a ${VAR} reference has
+// neither a value in the environment nor a default.
+var ErrUnsetVariable = errors.New("unset variable")
+
+// ErrInvalidReference is matched (errors.Is) by the error Parse returns when a ${...} reference is
+// unterminated or does not name a variable.
+var ErrInvalidReference = errors.New("invalid variable reference")
+
+// Expand replaces ${VAR} and ${VAR:-default} references in s with values from lookup. The default
+// applies when the variable is unset or empty. "$${" produces a literal "${", which keeps Docker
+// build-arg references such as $${TARGET_DOTNET_VERSION} intact; other "$" sequences are kept as is.
+func Expand(s string, lookup func(string) (string, bool)) (string, error) {
+	expanded, errs := expand(s, lookup)
+	if len(errs) > 0 {
+		return "", errs[0]
+	}
+	return expanded, nil
+}
+
+// expand is Expand that goes on after a reference it cannot resolve (kept as written), returning
+// one error for each of them so they are all reported at once.
+func expand(s string, lookup func(string) (string, bool)) (string, []error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var b strings.Builder
+	var errs []error
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "$${"):
+			b.WriteString("${")
+			i += 3
+		case strings.HasPrefix(s[i:], "${"):
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				errs = append(errs, fmt.Errorf("unterminated %w in %q", ErrInvalidReference, s))
+				b.WriteString(s[i:])
+				return b.String(), errs
+			}
+			value, err := expandReference(s[i+2:i+end], lookup)
+			if err != nil {
+				errs = append(errs, err)
+				value = s[i : i+end+1]
+			}
+			b.WriteString(value)
+			i += end + 1
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return b.String(), errs
+}
+
+// expandReference resolves the inside of one ${...} reference.
+func expandReference(ref string, lookup func(string) (string, bool)) (string, error) {
+	name, def, hasDefault := strings.Cut(ref, ":-")
+	if !validVariableName(name) {
+		return "", fmt.Errorf("%w ${%s}", ErrInvalidReference, ref)
+	}
+	if value, ok := lookup(name); ok && (value != "" || !hasDefault) {
+		return value, nil
+	}
+	if hasDefault {
+		return def, nil
+	}
+	return "", fmt.Errorf("%w %s (write $${%s} for a literal ${%s} such as a Docker build arg, "+
+		"or set it or give a default with ${%s:-default})", ErrUnsetVariable, name, name, name, name)
+}
+
+func validVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c != '_' && (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// interpolate expands the environment references of every value scalar below n in place
+// (mapping keys are left alone) and returns one diagnostic per failed reference. Fields tagged
+// expand:"-" hold raw Dockerfile text and are left untouched, so ${...} in them stays a Docker
+// variable.
+func interpolate(file string, n *yaml.Node) []Diagnostic {
+	var diags []Diagnostic
+	var walk func(n *yaml.Node, t reflect.Type)
+	walk = func(n *yaml.Node, t reflect.Type) {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(c, t)
+			}
+		case yaml.SequenceNode:
+			if t != nil && t.Kind() == reflect.Slice {
+				t = t.Elem()
+			} else {
+				t = nil
+			}
+			for _, c := range n.Content {
+				walk(c, t)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if ct, ok := valueType(t, n.Content[i].Value); ok {
+					walk(n.Content[i+1], ct)
+				}
+			}
+		case yaml.ScalarNode:
+			expanded, errs := expand(n.Value, os.LookupEnv)
+			for _, err := range errs {
+				diags = append(diags, Diagnostic{File: file, Line: n.Line, Column: n.Column,
+					Message: err.Error(), Err: err})
+			}
+			n.Value = expanded
+		}
+	}
+	walk(n, reflect.TypeFor[Config]())
+	return diags
+}
+
+// valueType returns the type of the value under key in a mapping of type t (nil when unknown, such
+// as for keys outside the schema, which Validate reports), and false when the value is verbatim.
+func valueType(t reflect.Type, key string) (reflect.Type, bool) {
+	if t == nil {
+		return nil, true
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem(), true
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if name, _, _ := strings.Cut(f.Tag.Get("yaml"), ","); name == key {
+				return f.Type, f.Tag.Get("expand") != "-"
+			}
+		}
+	}
+	return nil, true
+}
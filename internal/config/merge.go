@@ -0,0 +1,239 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// List merge modes, set with the `merge` struct tag on slice fields. Lists replace by default.
+const (
+	mergeAppend  = "append"  // items of deeper files are added after the inherited ones
+	mergeReplace = "replace" // the deepest file that sets the key wins
+)
+
+// Layer is one parsed configuration file of a cascade.
+type Layer struct {
+	Path   string
+	Config Config
+	// root is the top-level mapping, used to tell keys that are set apart from zero values.
+	root *yaml.Node
+}
+
+// Source records the effective value of a key and the files it came from.
+type Source struct {
+	Key   string   // dotted key, e.g. base.packages
+	Value string   // effective value; list items are joined with ", "
+	Files []string // contributing files, repository root first
+}
+
+// Merge combines layers ordered from the repository root down to the project. Scalars set in a
+// deeper file override inherited ones (nearest wins); lists are appended to or replaced according
+// to their `merge` tag. The returned sources cover every key set by at least one layer, in schema
+// order.
+func Merge(layers []Layer) (Config, []Source) {
+	var cfg Config
+	files := map[string][]string{}
+	for _, l := range layers {
+		if l.root == nil {
+			continue
+		}
+		mergeValue(reflect.ValueOf(&cfg).Elem(), reflect.ValueOf(l.Config), l.root, "", "", l.Path, files)
+	}
+	return cfg, sources(reflect.ValueOf(cfg), "", files)
+}
+
+// mergeValue merges src into dst for the keys present in n.
+func mergeValue(dst, src reflect.Value, n *yaml.Node, key, mode, path string, files map[string][]string) {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	switch dst.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			f, ok := fieldByKey(dst.Type(), n.Content[i].Value)
+			if !ok {
+				continue
+			}
+			mergeValue(dst.FieldByIndex(f.Index), src.FieldByIndex(f.Index), n.Content[i+1],
+				joinKey(key, n.Content[i].Value), f.Tag.Get("merge"), path, files)
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			name := reflect.ValueOf(n.Content[i].Value)
+			from := src.MapIndex(name)
+			if !from.IsValid() {
+				continue
+			}
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if cur := dst.MapIndex(name); cur.IsValid() {
+				elem.Set(cur)
+			}
+			mergeValue(elem, from, n.Content[i+1], joinKey(key, n.Content[i].Value), "", path, files)
+			dst.SetMapIndex(name, elem)
+		}
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			return
+		}
+		switch mode {
+		case mergeAppend:
+			dst.Set(reflect.AppendSlice(dst, src))
+			files[key] = append(files[key], path)
+		case mergeReplace, "":
+			dst.Set(src)
+			files[key] = []string{path}
+		}
+	case reflect.String:
+		if src.String() == "" {
+			return
+		}
+		dst.Set(src)
+		files[key] = []string{path}
+	}
+}
+
+func sources(v reflect.Value, prefix string, files map[string][]string) []Source {
+	var out []Source
+	t := v.Type()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		key := joinKey(prefix, name)
+		fv := v.Field(i)
+		switch fv.Kind() {
+		case reflect.Struct:
+			out = append(out, sources(fv, key, files)...)
+			continue
+		case reflect.Map:
+			keys := fv.MapKeys()
+			slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+			for _, k := range keys {
+				entryKey := joinKey(key, k.String())
+				if elem := fv.MapIndex(k); elem.Kind() == reflect.Struct {
+					out = append(out, sources(elem, entryKey, files)...)
+				} else if from, ok := files[entryKey]; ok {
+					out = append(out, Source{Key: entryKey, Value: elem.String(), Files: from})
+				}
+			}
+			continue
+		case reflect.Slice:
+			if from, ok := files[key]; ok {
+				out = append(out, Source{Key: key, Value: strings.Join(fv.Interface().([]string), ", "), Files: from})
+			}
+		default:
+			if from, ok := files[key]; ok {
+				out = append(out, Source{Key: key, Value: fv.String(), Files: from})
+			}
+		}
+	}
+	return out
+}
+
+// fieldByKey returns the struct field whose yaml name is key.
+func fieldByKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if name, _, _ := strings.Cut(f.Tag.Get("yaml"), ","); name == key {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// ApplyProfile returns cfg with the named profile merged over its top-level sections: scalars set
+// in the profile win and lists are appended to or replaced according to their `merge` tag. The
+// sources (as returned by Merge) are updated to point at the files defining the profile, labeled
+// with its name.
+func ApplyProfile(cfg Config, srcs []Source, name string) (Config, []Source, error) {
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		available := "none defined"
+		if names := cfg.ProfileNames(); len(names) > 0 {
+			available = "available: " + strings.Join(names, ", ")
+		}
+		return cfg, srcs, fmt.Errorf("unknown profile '%s' (%s)", name, available)
+	}
+	files := make(map[string][]string, len(srcs))
+	for _, s := range srcs {
+		files[s.Key] = s.Files
+	}
+	profileKey := joinKey("profiles", name)
+	for key, from := range files {
+		if strings.HasPrefix(key, profileKey+".") {
+			labeled := make([]string, len(from))
+			for i, f := range from {
+				labeled[i] = f + " (profile " + name + ")"
+			}
+			files[key] = labeled
+		}
+	}
+	applyValue(reflect.ValueOf(&cfg).Elem(), reflect.ValueOf(p), "", profileKey, "", files)
+	return cfg, sources(reflect.ValueOf(cfg), "", files), nil
+}
+
+// applyValue merges the non-zero values of the profile value src into dst. Keys are matched by
+// yaml name; key is the dotted key in dst and profileKey the same key under the profile.
+func applyValue(dst, src reflect.Value, key, profileKey, mode string, files map[string][]string) {
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := range src.NumField() {
+			sf := src.Type().Field(i)
+			name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+			df, ok := fieldByKey(dst.Type(), name)
+			if !ok {
+				continue
+			}
+			applyValue(dst.FieldByIndex(df.Index), src.Field(i), joinKey(key, name), joinKey(profileKey, name),
+				df.Tag.Get("merge"), files)
+		}
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		merged := reflect.MakeMap(dst.Type()) // copied so the top-level map is left untouched
+		for iter := dst.MapRange(); iter.Next(); {
+			merged.SetMapIndex(iter.Key(), iter.Value())
+		}
+		for iter := src.MapRange(); iter.Next(); {
+			merged.SetMapIndex(iter.Key(), iter.Value())
+			files[joinKey(key, iter.Key().String())] = files[joinKey(profileKey, iter.Key().String())]
+		}
+		dst.Set(merged)
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		if mode == mergeAppend {
+			dst.Set(reflect.AppendSlice(reflect.AppendSlice(reflect.MakeSlice(dst.Type(), 0, dst.Len()+src.Len()), dst), src))
+			files[key] = append(slices.Clone(files[key]), files[profileKey]...)
+			return
+		}
+		dst.Set(src)
+		files[key] = files[profileKey]
+	case reflect.String:
+		if src.String() == "" {
+			return
+		}
+		dst.Set(src)
+		files[key] = files[profileKey]
+	}
+}
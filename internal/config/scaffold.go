@@ -0,0 +1,93 @@
+package config
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// scaffoldTemplate renders a commented .dockerbuild. Optional sections are emitted commented out
+// so users can discover them without changing the generated Dockerfile.
+const scaffoldTemplate = `# dockerfile-gen configuration (created by 'dockerfile-gen init').
+# Values below are what was detected for this project; edit as needed.
+# Values may reference environment variables as ${VAR} or ${VAR:-default};
+# write $${ for a literal ${ (for example a Docker build arg).
+
+# Generator to use (dotnet, go). Remove to rely on autodetection.
+language: {{ quote .Language }}
+{{ if .Dotnet.SdkVersion }}
+# .NET settings.
+dotnet:
+  # Target .NET version (TARGET_DOTNET_VERSION build arg), detected from <TargetFramework>.
+  sdk-version: {{ quote .Dotnet.SdkVersion }}
+{{ end }}
+# Runtime stage image and extra apk packages.
+base:
+  image: {{ quote .Base.Image }}
+  # packages:
+  #   - ca-certificates
+
+# Build stage image and extra apk packages.
+base-build:
+  image: {{ quote .BaseBuild.Image }}
+  # packages:
+  #   - git
+
+# Final stage settings: commands run before the entrypoint, exposed ports,
+# environment variables, image labels, the working directory, entrypoint and
+# default arguments, and the health check.
+# final:
+#   run:
+#     - adduser -D app
+#   ports:
+#     - "8080"
+#   env:
+#     ASPNETCORE_ENVIRONMENT: Production
+#   labels:
+#     org.opencontainers.image.source: https://github.com/org/repo
+#   workdir: /app
+#   entrypoint: ["/app/docker-entrypoint.sh"]
+#   cmd: ["--help"]
+#   healthcheck:
+#     command: wget --no-verbose --tries=1 --spider http://localhost:8080/health || exit 1
+#     interval: 30s
+
+# Dockerfile lines inserted at hook points of the template: build-pre-restore,
+# build-post-restore, build-post-build, final-pre-entrypoint, final-extra.
+# hooks:
+#   build-pre-restore:
+#     snippet: RUN apk add --no-cache git
+
+# Extra stages placed before the final stage; from is a stage (e.g. build) or an image.
+# stages:
+#   tools:
+#     from: alpine:3.20
+#     instructions:
+#       - RUN apk add --no-cache curl
+#     copy-into-final:
+#       /usr/bin/curl: /usr/local/bin/curl
+
+# Named overrides of the sections above, selected with --profile or $DOCKERFILE_GEN_PROFILE.
+# profiles:
+#   debug:
+#     base:
+#       packages:
+#         - strace
+`
+
+// Scaffold writes a commented configuration file for cfg to w.
+func Scaffold(w io.Writer, cfg Config) error {
+	tmpl, err := template.New("dockerbuild").
+		Funcs(template.FuncMap{"quote": quote}).
+		Parse(scaffoldTemplate)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, cfg)
+}
+
+// quote renders s as a YAML double-quoted string that Parse reads back unchanged.
+func quote(s string) string {
+	return strconv.Quote(strings.ReplaceAll(s, "${", "$${"))
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SchemaDialect is the JSON Schema draft the generated schema conforms to.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// interpolatedValue matches any string holding a ${VAR} reference; such values are only checked
+// after expansion, so the schema accepts them for constrained keys.
+const interpolatedValue = `.*\$\{[^}]+\}.*`
+
+// Schema returns the JSON Schema of a .dockerbuild file, generated from the Config struct tree:
+// property names come from the yaml tags, descriptions and defaults from the desc and default tags,
+// and constraints from the validate tags. languages is the enum of the language key.
+func Schema(languages []string) map[string]any {
+	s := schemaFor(reflect.TypeFor[Config]())
+	s["$schema"] = SchemaDialect
+	s["title"] = DefaultDockerBuildFileName
+	s["description"] = "dockerfile-gen project configuration. Files are merged from the repository root down to the project."
+	s["type"] = "object" // the document itself may not be null
+	if len(languages) > 0 {
+		props := s["properties"].(map[string]any)
+		props["language"].(map[string]any)["enum"] = languages
+	}
+	return s
+}
+
+func schemaFor(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Struct:
+		props := make(map[string]any, t.NumField())
+		for i := range t.NumField() {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			p := schemaFor(f.Type)
+			if d := f.Tag.Get("desc"); d != "" {
+				p["description"] = d
+			}
+			if d, ok := f.Tag.Lookup("default"); ok {
+				p["default"] = d
+			}
+			applyRule(p, f.Tag.Get("validate"))
+			props[name] = p
+		}
+		return map[string]any{"type": []string{"object", "null"}, "properties": props, "additionalProperties": false}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 []string{"object", "null"},
+			"propertyNames":        map[string]any{"minLength": 1},
+			"additionalProperties": schemaFor(t.Elem()),
+		}
+	case reflect.Slice:
+		return map[string]any{"type": []string{"array", "null"}, "items": schemaFor(t.Elem())}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+// applyRule adds the constraints of a validate tag to s: to its items for lists and values for
+// maps, or to its property names for keys: rules.
+func applyRule(s map[string]any, tag string) {
+	keys, values := splitRules(tag)
+	for _, rule := range keys {
+		addConstraint(s["propertyNames"].(map[string]any), rule)
+	}
+	target := s
+	if items, ok := s["items"].(map[string]any); ok {
+		target = items
+	} else if elem, ok := s["additionalProperties"].(map[string]any); ok {
+		target = elem
+	}
+	for _, rule := range values {
+		if rule == ruleItems {
+			s["minItems"] = 1
+			continue
+		}
+		addConstraint(target, rule)
+	}
+}
+
+func addConstraint(s map[string]any, rule string) {
+	switch rule {
+	case ruleNonEmpty:
+		s["minLength"] = 1
+	case ruleVersion:
+		s["pattern"] = "^(" + versionExpr + "|" + interpolatedValue + ")$"
+	case rulePort:
+		s["pattern"] = "^(" + portExpr + "|" + interpolatedValue + ")$"
+	case ruleDuration:
+		s["pattern"] = "^(" + durationExpr + "|" + interpolatedValue + ")$"
+	case ruleCount:
+		s["pattern"] = "^(" + countExpr + "|" + interpolatedValue + ")$"
+	case ruleHook:
+		s["enum"] = HookNames
+	case ruleEnvName:
+		s["pattern"] = "^" + envNameExpr + "$"
+	case ruleStageName:
+		s["pattern"] = "^" + stageNameExpr + "$"
+	case ruleSingleLine:
+		s["pattern"] = `^[^\r\n]*$`
+	}
+}
@@ -0,0 +1,403 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Diagnostic is a problem found in a configuration file, located by line and column (1-based;
+// Column is 0 when unknown).
+type Diagnostic struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+	Err     error // underlying error, if any (see ErrUnsetVariable)
+}
+
+func (d Diagnostic) String() string {
+	if d.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+}
+
+// ValidationError reports every diagnostic found in a configuration file.
+type ValidationError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		lines[i] = d.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the underlying errors of the diagnostics so errors.Is can match them.
+func (e *ValidationError) Unwrap() []error {
+	var errs []error
+	for _, d := range e.Diagnostics {
+		if d.Err != nil {
+			errs = append(errs, d.Err)
+		}
+	}
+	return errs
+}
+
+// Value rules, set with the comma-separated `validate` struct tag. On slices and maps they apply
+// to every item; rules prefixed with keysPrefix apply to the keys of a map instead.
+const (
+	ruleNonEmpty   = "nonempty"
+	ruleVersion    = "version"    // major.minor, e.g. 9.0
+	rulePort       = "port"       // 1-65535 with an optional /tcp or /udp suffix
+	ruleEnvName    = "envname"    // environment variable name
+	ruleSingleLine = "singleline" // no line breaks
+	ruleDuration   = "duration"   // Docker duration, e.g. 30s or 1m30s
+	ruleCount      = "count"      // non-negative integer
+	ruleItems      = "items"      // on a list: at least one item (checked on the list itself)
+	ruleHook       = "hook"       // one of HookNames
+	ruleStageName  = "stagename"  // Dockerfile stage name
+
+	keysPrefix = "keys:"
+)
+
+// Syntax of the pattern-based rules, shared with the JSON Schema.
+const (
+	versionExpr  = `[0-9]+\.[0-9]+`
+	portExpr     = `[0-9]{1,5}(/(tcp|udp))?`
+	envNameExpr  = `[A-Za-z_][A-Za-z0-9_]*`
+	durationExpr = `([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+`
+	countExpr    = `[0-9]+`
+	// Docker lowercases stage names; requiring lowercase keeps names and references comparable.
+	stageNameExpr = `[a-z][a-z0-9_.-]*`
+)
+
+var (
+	versionPattern   = regexp.MustCompile(`^` + versionExpr + `$`)
+	portPattern      = regexp.MustCompile(`^` + portExpr + `$`)
+	envNamePattern   = regexp.MustCompile(`^` + envNameExpr + `$`)
+	durationPattern  = regexp.MustCompile(`^` + durationExpr + `$`)
+	countPattern     = regexp.MustCompile(`^` + countExpr + `$`)
+	stageNamePattern = regexp.MustCompile(`^` + stageNameExpr + `$`)
+)
+
+// splitRules separates the rules of a validate tag that apply to map keys from the others.
+func splitRules(tag string) (keys, values []string) {
+	if tag == "" {
+		return nil, nil
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		if r, ok := strings.CutPrefix(rule, keysPrefix); ok {
+			keys = append(keys, r)
+		} else {
+			values = append(values, rule)
+		}
+	}
+	return keys, values
+}
+
+// yamlSyntaxError matches the position prefix of yaml.v3 syntax errors.
+var yamlSyntaxError = regexp.MustCompile(`^yaml: line ([0-9]+): (.*)$`)
+
+// Validate checks a parsed .dockerbuild document against the Config schema and returns one
+// diagnostic per unknown key, wrong type or invalid value.
+func Validate(file string, doc *yaml.Node) []Diagnostic {
+	diags, _ := validate(file, doc)
+	return diags
+}
+
+// validate is Validate that also returns the nodes the diagnostics are about (see prune).
+func validate(file string, doc *yaml.Node) ([]Diagnostic, map[*yaml.Node]bool) {
+	v := validator{file: file, invalid: map[*yaml.Node]bool{}}
+	if doc.Kind == 0 { // empty file
+		return nil, nil
+	}
+	if doc.Kind == yaml.DocumentNode {
+		if len(doc.Content) == 0 {
+			return nil, nil
+		}
+		doc = doc.Content[0]
+	}
+	v.node(doc, reflect.TypeFor[Config](), "", "")
+	return v.diags, v.invalid
+}
+
+type validator struct {
+	file    string
+	diags   []Diagnostic
+	invalid map[*yaml.Node]bool
+}
+
+func (v *validator) addf(n *yaml.Node, format string, a ...any) {
+	v.diags = append(v.diags, Diagnostic{File: v.file, Line: n.Line, Column: n.Column, Message: fmt.Sprintf(format, a...)})
+	v.invalid[n] = true
+}
+
+// prune removes from the tree below n every entry whose key or value is in invalid, and every
+// list item in invalid, so that decoding ignores what Validate reported instead of applying it.
+// A list left empty is removed too: an empty list would replace the inherited or default one.
+func prune(n *yaml.Node, invalid map[*yaml.Node]bool) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			prune(c, invalid)
+		}
+	case yaml.SequenceNode:
+		kept := n.Content[:0]
+		for _, c := range n.Content {
+			if !invalid[c] {
+				prune(c, invalid)
+				kept = append(kept, c)
+			}
+		}
+		n.Content = kept
+	case yaml.MappingNode:
+		kept := n.Content[:0]
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, val := n.Content[i], n.Content[i+1]
+			if invalid[k] || invalid[val] {
+				continue
+			}
+			items := len(val.Content)
+			prune(val, invalid)
+			if val.Kind == yaml.SequenceNode && items > 0 && len(val.Content) == 0 {
+				continue
+			}
+			kept = append(kept, k, val)
+		}
+		n.Content = kept
+	}
+}
+
+// node validates n against t; key is the dotted path used in messages, rule the field's value rule.
+func (v *validator) node(n *yaml.Node, t reflect.Type, key, rule string) {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	null := n.Kind == yaml.ScalarNode && n.Tag == "!!null"
+	switch t.Kind() {
+	case reflect.Struct:
+		if null {
+			return
+		}
+		if n.Kind != yaml.MappingNode {
+			v.addf(n, "%s must be a mapping, got %s", describeKey(key), describeNode(n))
+			return
+		}
+		v.mapping(n, t, key)
+	case reflect.Slice:
+		if null {
+			return
+		}
+		if n.Kind != yaml.SequenceNode {
+			v.addf(n, "%s must be a list, got %s", describeKey(key), describeNode(n))
+			return
+		}
+		if _, rules := splitRules(rule); slices.Contains(rules, ruleItems) && len(n.Content) == 0 {
+			v.addf(n, "%s must not be an empty list", describeKey(key))
+		}
+		for i, item := range n.Content {
+			v.node(item, t.Elem(), fmt.Sprintf("%s[%d]", key, i), rule)
+		}
+	case reflect.Map:
+		if null {
+			return
+		}
+		if n.Kind != yaml.MappingNode {
+			v.addf(n, "%s must be a mapping, got %s", describeKey(key), describeNode(n))
+			return
+		}
+		keyRules, valueRules := splitRules(rule)
+		seen := make(map[string]bool, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k := n.Content[i]
+			switch {
+			case strings.TrimSpace(k.Value) == "":
+				v.addf(k, "%s keys must not be empty", describeKey(key))
+			case seen[k.Value]:
+				v.addf(k, "duplicate key '%s'", joinKey(key, k.Value))
+			default:
+				seen[k.Value] = true
+				v.value(k, describeKey(key)+" key", keyRules, false)
+				v.node(n.Content[i+1], t.Elem(), joinKey(key, k.Value), strings.Join(valueRules, ","))
+			}
+		}
+	case reflect.String:
+		if n.Kind != yaml.ScalarNode {
+			v.addf(n, "%s must be a string, got %s", describeKey(key), describeNode(n))
+			return
+		}
+		_, rules := splitRules(rule)
+		v.value(n, describeKey(key), rules, null)
+	}
+}
+
+func (v *validator) mapping(n *yaml.Node, t reflect.Type, prefix string) {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	var names []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = f
+		names = append(names, name)
+	}
+	seen := make(map[string]bool, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, val := n.Content[i], n.Content[i+1]
+		key := k.Value
+		if prefix != "" {
+			key = prefix + "." + k.Value
+		}
+		if seen[k.Value] {
+			v.addf(k, "duplicate key '%s'", key)
+			continue
+		}
+		seen[k.Value] = true
+		f, ok := fields[k.Value]
+		if !ok {
+			msg := fmt.Sprintf("unknown key '%s'", key)
+			if s := suggest(k.Value, names); s != "" {
+				msg += fmt.Sprintf(" (did you mean '%s'?)", s)
+			}
+			v.addf(k, "%s", msg)
+			continue
+		}
+		v.node(val, f.Type, key, f.Tag.Get("validate"))
+	}
+}
+
+// value checks the scalar n against rules; what names it in messages.
+func (v *validator) value(n *yaml.Node, what string, rules []string, null bool) {
+	for _, rule := range rules {
+		switch rule {
+		case ruleNonEmpty:
+			if null || strings.TrimSpace(n.Value) == "" {
+				v.addf(n, "%s must not be empty", what)
+			}
+		case ruleVersion:
+			if !null && !versionPattern.MatchString(n.Value) {
+				v.addf(n, "%s must be a major.minor version such as 9.0, got %s", what, strconv.Quote(n.Value))
+			}
+		case rulePort:
+			if !null && !validPort(n.Value) {
+				v.addf(n, "%s must be a port number (1-65535) optionally followed by /tcp or /udp, got %s",
+					what, strconv.Quote(n.Value))
+			}
+		case ruleEnvName:
+			if !envNamePattern.MatchString(n.Value) {
+				v.addf(n, "%s must be a valid environment variable name, got %s", what, strconv.Quote(n.Value))
+			}
+		case ruleDuration:
+			if !null && !durationPattern.MatchString(n.Value) {
+				v.addf(n, "%s must be a duration such as 30s or 1m30s, got %s", what, strconv.Quote(n.Value))
+			}
+		case ruleCount:
+			if !null && !countPattern.MatchString(n.Value) {
+				v.addf(n, "%s must be a non-negative integer, got %s", what, strconv.Quote(n.Value))
+			}
+		case ruleHook:
+			if !slices.Contains(HookNames, n.Value) {
+				msg := fmt.Sprintf("unknown hook '%s'", n.Value)
+				if s := suggest(n.Value, HookNames); s != "" {
+					msg += fmt.Sprintf(" (did you mean '%s'?)", s)
+				}
+				v.addf(n, "%s; available hooks: %s", msg, strings.Join(HookNames, ", "))
+				delete(v.invalid, n) // kept so that rendering fails on it instead of dropping the hook
+			}
+		case ruleStageName:
+			if !stageNamePattern.MatchString(n.Value) {
+				v.addf(n, "%s must be a stage name (lowercase letter followed by lowercase letters, digits, '_', '.' or '-'), got %s",
+					what, strconv.Quote(n.Value))
+			}
+		case ruleSingleLine:
+			if strings.ContainsAny(n.Value, "\r\n") {
+				v.addf(n, "%s must fit on a single line", what)
+			}
+		}
+	}
+}
+
+func validPort(s string) bool {
+	if !portPattern.MatchString(s) {
+		return false
+	}
+	number, _, _ := strings.Cut(s, "/")
+	port, err := strconv.Atoi(number)
+	return err == nil && port >= 1 && port <= 65535
+}
+
+func describeKey(key string) string {
+	if key == "" {
+		return "the document"
+	}
+	return "'" + key + "'"
+}
+
+func describeNode(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return strconv.Quote(n.Value)
+	}
+}
+
+// suggest returns the known key closest to an unknown one, or "" when none is close enough.
+func suggest(key string, known []string) string {
+	normalize := func(s string) string {
+		return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(s))
+	}
+	best, bestDist := "", 3
+	for _, k := range known {
+		if normalize(k) == normalize(key) {
+			return k
+		}
+		if d := levenshtein(key, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// syntaxDiagnostic converts a yaml.v3 syntax error into a diagnostic when it carries a line number.
+func syntaxDiagnostic(file string, err error) (Diagnostic, bool) {
+	m := yamlSyntaxError.FindStringSubmatch(err.Error())
+	if m == nil {
+		return Diagnostic{}, false
+	}
+	line, _ := strconv.Atoi(m[1])
+	return Diagnostic{File: file, Line: line, Message: m[2]}, true
+}
@@ -0,0 +1,116 @@
+// Package discover walks a repository tree to find projects handled by the registered generators.
+package discover
+
+import (
+	"io/fs"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
+)
+
+// skippedDirs are never descended into; they hold build output or third-party code.
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"bin":          true,
+	"obj":          true,
+	"vendor":       true,
+}
+
+// Project is a directory detected by one of the generators.
+type Project struct {
+	Path     string // absolute directory path
+	RelPath  string // slash-separated path relative to the repository root ("." for the root itself)
+	Language string
+}
+
+// Options filters which directories are considered. Patterns are matched against RelPath
+// and support '**' to match any number of path segments.
+type Options struct {
+	Include []string
+	Exclude []string
+}
+
+// Find walks root and returns every directory detected by one of gens, in walk order.
+// The first generator (in slice order) whose Detect succeeds claims the directory.
+// Excluded directories are skipped together with their subtree.
+func Find(root string, gens []generator.Generator, opts Options) ([]Project, error) {
+	var result []Project
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, relErr := filepath.Rel(root, p)
+		if relErr != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if rel != "." && (strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()]) {
+			return filepath.SkipDir
+		}
+		if matchAny(opts.Exclude, rel) {
+			slog.Debug("discover: directory excluded", "dir", rel)
+			return filepath.SkipDir
+		}
+		if len(opts.Include) > 0 && !matchAny(opts.Include, rel) {
+			return nil
+		}
+		for _, g := range gens {
+			if ok, _ := g.Detect(p); ok {
+				slog.Debug("discover: project detected", "dir", rel, "language", g.Name())
+				result = append(result, Project{Path: p, RelPath: rel, Language: g.Name()})
+				break
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether the slash-separated path rel matches pattern.
+// Segments are matched with path.Match; a '**' segment matches zero or more segments.
+func Match(pattern, rel string) bool {
+	pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+	rel = strings.Trim(rel, "/")
+	if pattern == "" {
+		return rel == "" || rel == "."
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(rest, parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], parts[0]); err != nil || !ok {
+			return false
+		}
+		pattern = pattern[1:]
+		parts = parts[1:]
+	}
+	return len(parts) == 0
+}
@@ -0,0 +1,97 @@
+package discover
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/common"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
+)
+
+// markerGen detects directories containing a file with the given name.
+type markerGen struct{ name, marker string }
+
+func (m markerGen) Name() string { return m.name }
+func (m markerGen) Detect(p string) (bool, error) {
+	_, err := os.Stat(filepath.Join(p, m.marker))
+	return err == nil, nil
+}
+func (m markerGen) Load(*slog.Logger, string, string) (generator.ProjectData, []common.AdditionalFilePath, error) {
+	return nil, nil, nil
+}
+func (m markerGen) GenerateDockerfile(*slog.Logger, generator.ProjectData, []common.AdditionalFilePath, io.Writer, config.Config) error {
+	return nil
+}
+
+func touch(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	touch(t, filepath.Join(root, "services", "api", "go.mod"))
+	touch(t, filepath.Join(root, "services", "web", "web.csproj"))
+	touch(t, filepath.Join(root, "services", "legacy", "old", "go.mod"))
+	touch(t, filepath.Join(root, "node_modules", "pkg", "go.mod"))
+	touch(t, filepath.Join(root, ".hidden", "go.mod"))
+	gens := []generator.Generator{markerGen{"dotnet", "web.csproj"}, markerGen{"go", "go.mod"}}
+
+	projects, err := Find(root, gens, Options{})
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	got := map[string]string{}
+	for _, p := range projects {
+		got[p.RelPath] = p.Language
+	}
+	want := map[string]string{"services/api": "go", "services/web": "dotnet", "services/legacy/old": "go"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("expected %s => %s, got %v", k, v, got)
+		}
+	}
+
+	projects, err = Find(root, gens, Options{Include: []string{"services/*"}, Exclude: []string{"services/legacy"}})
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 filtered projects, got %+v", projects)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		pattern, rel string
+		want         bool
+	}{
+		{"services/*", "services/api", true},
+		{"services/*", "services/api/v2", false},
+		{"services/**", "services/api/v2", true},
+		{"**/api", "services/api", true},
+		{"**/api", "api", true},
+		{"**", "anything/at/all", true},
+		{"src/**/tests", "src/tests", true},
+		{"src/**/tests", "src/a/b/tests", true},
+		{"src/**/tests", "src/a/b/other", false},
+		{"", ".", true},
+	}
+	for _, c := range cases {
+		if got := Match(c.pattern, c.rel); got != c.want {
+			t.Fatalf("Match(%q, %q) = %v, want %v", c.pattern, c.rel, got, c.want)
+		}
+	}
+}
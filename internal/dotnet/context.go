@@ -28,19 +28,24 @@ func newSearchCache() *searchCache {
 }
 
 // LoadProjectContextFromProject discovers additional context files (nuget.config, Directory.* props) for the whole project graph.
-func LoadProjectContextFromProject(project Project, rootPath string) ([]common.AdditionalFilePath, error) {
+// Each file's Reason names the project whose upward walk found it first.
+func LoadProjectContextFromProject(log *slog.Logger, project Project, rootPath string) ([]common.AdditionalFilePath, error) {
 	var additionalPaths []common.AdditionalFilePath
 	seen := map[string]bool{}
 	cache := newSearchCache()
 	for _, p := range project.GetAllProjectReferences() {
-		slog.Info("Looking for project context file", "path", p.Path)
+		log.Info("Looking for project context file", "path", p.Path)
 		paths, err := loadProjectContextCached(p.Path, rootPath, cache)
 		if err != nil {
 			return nil, err
 		}
 		for _, f := range paths {
 			if !seen[f] {
-				additionalPaths = append(additionalPaths, common.AdditionalFilePath{Path: f, RootPath: rootPath})
+				reason := "found walking up from " + p.GetRelativePath()
+				if f == cache.nugetConfigPath {
+					reason = "first " + nugetFileName + " found scanning the repository root"
+				}
+				additionalPaths = append(additionalPaths, common.AdditionalFilePath{Path: f, RootPath: rootPath, Reason: reason})
 				seen[f] = true
 			}
 		}
@@ -48,6 +53,41 @@ func LoadProjectContextFromProject(project Project, rootPath string) ([]common.A
 	return additionalPaths, nil
 }
 
+// IsContextCandidate reports whether a file at path would be discovered by
+// LoadProjectContextFromProject for project, whether or not it exists: a nuget.config anywhere
+// below the repository root, or a Directory.Build.props / Directory.Packages.props in a directory
+// from a project of the graph up to the repository root. Names compare case-insensitively.
+func IsContextCandidate(project Project, path string) bool {
+	root, path := absPath(project.RootPath), absPath(path)
+	name := filepath.Base(path)
+	if strings.EqualFold(name, nugetFileName) {
+		rel, err := filepath.Rel(root, path)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+	}
+	if !strings.EqualFold(name, directoryBuildPropsName) && !strings.EqualFold(name, directoryPackagesProps) {
+		return false
+	}
+	dir := filepath.Dir(path)
+	for _, p := range project.GetAllProjectReferences() {
+		for current := filepath.Dir(absPath(p.Path)); ; current = filepath.Dir(current) {
+			if current == dir {
+				return true
+			}
+			if current == root || current == filepath.Dir(current) {
+				break
+			}
+		}
+	}
+	return false
+}
+
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
 func loadProjectContextCached(path, rootPath string, cache *searchCache) ([]string, error) {
 	var paths []string
 	if !cache.nugetConfigSearched {
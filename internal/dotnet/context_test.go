@@ -1,8 +1,10 @@
 package dotnet
 
 import (
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -27,11 +29,11 @@ func TestLoadProjectContextFromProject(t *testing.T) {
 	if err := os.WriteFile(projPath, []byte(projContent), 0o600); err != nil {
 		t.Fatalf("write proj: %v", err)
 	}
-	proj, err := LoadProject(projPath, root)
+	proj, err := LoadProject(slog.Default(), projPath, root)
 	if err != nil {
 		t.Fatalf("load project: %v", err)
 	}
-	additional, err := LoadProjectContextFromProject(proj, root)
+	additional, err := LoadProjectContextFromProject(slog.Default(), proj, root)
 	if err != nil {
 		t.Fatalf("context: %v", err)
 	}
@@ -42,9 +44,33 @@ func TestLoadProjectContextFromProject(t *testing.T) {
 	for _, a := range additional {
 		if filepath.Base(a.Path) == "nuget.config" {
 			foundNuget = true
+			if !strings.Contains(a.Reason, "repository root") {
+				t.Fatalf("unexpected nuget.config reason %q", a.Reason)
+			}
+		}
+		if filepath.Base(a.Path) == "Directory.Build.props" && a.Reason != "found walking up from src/App/App.csproj" {
+			t.Fatalf("unexpected Directory.Build.props reason %q", a.Reason)
 		}
 	}
 	if !foundNuget {
 		t.Fatalf("nuget.config not discovered")
 	}
 }
+
+func TestIsContextCandidate(t *testing.T) {
+	root := t.TempDir()
+	proj := Project{RootPath: root, Path: filepath.Join(root, "src", "App", "App.csproj")}
+	for path, want := range map[string]bool{
+		filepath.Join(root, "Directory.Build.props"):               true,
+		filepath.Join(root, "src", "directory.packages.props"):     true,
+		filepath.Join(root, "src", "App", "Directory.Build.props"): true,
+		filepath.Join(root, "docs", "Directory.Build.props"):       false,
+		filepath.Join(root, "tools", "nuget.config"):               true,
+		filepath.Join(filepath.Dir(root), "nuget.config"):          false,
+		filepath.Join(root, "src", "App", "Program.cs"):            false,
+	} {
+		if got := IsContextCandidate(proj, path); got != want {
+			t.Errorf("IsContextCandidate(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package dotnet
 
 import (
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
@@ -41,20 +43,20 @@ func TestDotnetGeneratorDetect(t *testing.T) {
 func TestDotnetGeneratorLoadErrors(t *testing.T) {
 	g := DotnetGenerator{}
 	empty := t.TempDir()
-	if _, _, err := g.Load(empty, empty); err == nil {
+	if _, _, err := g.Load(slog.Default(), empty, empty); err == nil {
 		t.Fatalf("expected error for empty directory")
 	}
 	// directory with multiple csproj
 	multi := t.TempDir()
 	writeFile(t, filepath.Join(multi, "A.csproj"), `<Project/>`)
 	writeFile(t, filepath.Join(multi, "B.csproj"), `<Project/>`)
-	if _, _, err := g.Load(multi, multi); err == nil {
+	if _, _, err := g.Load(slog.Default(), multi, multi); err == nil {
 		t.Fatalf("expected error for multiple csproj")
 	}
 	// non csproj file
 	non := filepath.Join(t.TempDir(), "file.txt")
 	writeFile(t, non, "hello")
-	if _, _, err := g.Load(non, filepath.Dir(non)); err == nil {
+	if _, _, err := g.Load(slog.Default(), non, filepath.Dir(non)); err == nil {
 		t.Fatalf("expected error for non csproj file")
 	}
 }
@@ -71,7 +73,7 @@ func TestProjectReferencesAndPackages(t *testing.T) {
 	rootFile := filepath.Join(root, "Root.csproj")
 	writeFile(t, rootFile,
 		`<Project><ItemGroup><ProjectReference Include="Child1.csproj"/><ProjectReference Include="Child2.csproj"/></ItemGroup></Project>`)
-	proj, err := LoadProject(rootFile, root)
+	proj, err := LoadProject(slog.Default(), rootFile, root)
 	if err != nil {
 		t.Fatalf("LoadProject: %v", err)
 	}
@@ -95,7 +97,7 @@ func TestCircularProjectReference(t *testing.T) {
 	projB := filepath.Join(root, "B.csproj")
 	writeFile(t, projA, `<Project><ItemGroup><ProjectReference Include="B.csproj"/></ItemGroup></Project>`)
 	writeFile(t, projB, `<Project><ItemGroup><ProjectReference Include="A.csproj"/></ItemGroup></Project>`)
-	_, err := LoadProject(projA, root)
+	_, err := LoadProject(slog.Default(), projA, root)
 	if err == nil {
 		t.Fatalf("expected circular reference error")
 	}
@@ -103,9 +105,7 @@ func TestCircularProjectReference(t *testing.T) {
 
 func TestGenerateDockerfileInvalidProjectType(t *testing.T) {
 	g := DotnetGenerator{}
-	dir := t.TempDir()
-	dest := filepath.Join(dir, "Dockerfile")
-	err := g.GenerateDockerfile(struct{}{}, nil, dest, config.Default())
+	err := g.GenerateDockerfile(slog.Default(), struct{}{}, nil, io.Discard, config.Default())
 	if err == nil {
 		t.Fatalf("expected error for invalid project type")
 	}
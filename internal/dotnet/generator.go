@@ -3,17 +3,53 @@ package dotnet
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
-	"text/template"
 
 	"github.com/n2jsoft-public-org/dockerfile-generator/internal/common"
 	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
 	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/templates"
 )
 
+const (
+	defaultBaseImage  = "mcr.microsoft.com/dotnet/aspnet:${TARGET_DOTNET_VERSION}-alpine"
+	defaultSdkImage   = "mcr.microsoft.com/dotnet/sdk:${TARGET_DOTNET_VERSION}-alpine"
+	defaultSdkVersion = "9.0"
+	defaultPort       = "8080"
+	defaultWorkdir    = "/app"
+)
+
+// defaultEnv is the environment of the runtime image; final.env entries override or extend it.
+var defaultEnv = map[string]string{
+	"DOTNET_SYSTEM_GLOBALIZATION_INVARIANT": "false",
+	"LC_ALL":                                "en_US.UTF-8",
+	"LANG":                                  "en_US.UTF-8",
+}
+
+// defaultEnvOrder is the order in which the ENV instruction lists the defaultEnv keys, the one
+// of the Dockerfiles generated before final.env existed; other keys follow, sorted.
+var defaultEnvOrder = []string{"DOTNET_SYSTEM_GLOBALIZATION_INVARIANT", "LC_ALL", "LANG"}
+
+// builtinStages are the stages of the embedded template before the final one; extra stages
+// (the stages config key) are placed after them.
+var builtinStages = []string{"base", "build", "publish"}
+
+// healthCheckPackages are the package ID prefixes (compared case-insensitively) of the libraries
+// that expose health check endpoints. A project graph referencing one gets an HTTP probe of
+// healthCheckPath unless final.healthcheck.command is set.
+var healthCheckPackages = []string{
+	"Microsoft.Extensions.Diagnostics.HealthChecks",
+	"Microsoft.AspNetCore.Diagnostics.HealthChecks",
+	"AspNetCore.HealthChecks.",
+}
+
+const healthCheckPath = "/health"
+
 // TemplateContext is the data model used to render the dotnet Dockerfile template.
 type TemplateContext struct {
 	AdditionalFilePaths []common.AdditionalFilePath
@@ -22,6 +58,13 @@ type TemplateContext struct {
 	BaseImage           string
 	BaseSdkImage        string
 	SdkVersion          string
+	BaseInstructions    []string // EXPOSE and ENV instructions of the base stage
+	FinalInstructions   []string // LABEL and HEALTHCHECK instructions of the final stage
+	Workdir             string   // WORKDIR of the final stage
+	Entrypoint          string   // ENTRYPOINT in exec form
+	Cmd                 string   // CMD in exec form, empty when not configured
+	Stages              []string // extra stages of the stages config key, in dependency order
+	FinalCopies         []string // COPY --from instructions of the final stage for the extra stages
 }
 
 // DotnetGenerator implements generator.Generator for .NET projects.
@@ -56,8 +99,96 @@ func (d DotnetGenerator) Detect(path string) (bool, error) {
 	return false, nil
 }
 
+// Describe returns the metadata listed by the languages command.
+func (d DotnetGenerator) Describe() generator.Description {
+	return generator.Description{
+		Summary:      ".NET projects: restore/build/publish stages with minimal project-graph copy before restore",
+		Detects:      []string{"a .csproj file", "a directory containing exactly one .csproj"},
+		ProjectFiles: []string{"*.csproj"},
+		ConfigKeys: []string{
+			"language", "template", "hooks", "stages", "dotnet.sdk-version",
+			"base.image", "base.packages", "base-build.image", "base-build.packages",
+			"final.run", "final.ports", "final.env", "final.labels", "final.healthcheck",
+			"final.workdir", "final.entrypoint", "final.cmd",
+		},
+	}
+}
+
+// DefaultImages returns the runtime and SDK images used when the config does not override them.
+func (d DotnetGenerator) DefaultImages() generator.ImageDefaults {
+	return generator.ImageDefaults{Base: defaultBaseImage, BaseBuild: defaultSdkImage}
+}
+
+// Template returns the embedded Dockerfile template.
+func (d DotnetGenerator) Template() string { return defaultTemplate }
+
+// SuggestConfig returns a configuration prefilled with the default images and the project's target framework.
+func (d DotnetGenerator) SuggestConfig(project generator.ProjectData) config.Config {
+	cfg := config.Config{
+		Language:  config.LanguageDotnet,
+		Dotnet:    config.DotnetConfig{SdkVersion: defaultSdkVersion},
+		Base:      config.ImageConfig{Image: defaultBaseImage},
+		BaseBuild: config.ImageConfig{Image: defaultSdkImage},
+	}
+	if proj, ok := project.(Project); ok && proj.SdkVersion() != "" {
+		cfg.Dotnet.SdkVersion = proj.SdkVersion()
+	}
+	return cfg
+}
+
+// ProjectInputs returns every .csproj of the transitive project graph.
+func (d DotnetGenerator) ProjectInputs(project generator.ProjectData) []string {
+	proj, ok := project.(Project)
+	if !ok {
+		return nil
+	}
+	var inputs []string
+	for _, p := range proj.GetAllProjectReferences() {
+		inputs = append(inputs, p.Path)
+	}
+	return inputs
+}
+
+// MatchesInput reports whether path is searched for context files of the project graph (see
+// IsContextCandidate).
+func (d DotnetGenerator) MatchesInput(project generator.ProjectData, path string) bool {
+	proj, ok := project.(Project)
+	return ok && IsContextCandidate(proj, path)
+}
+
+// ExplainProject renders the project reference tree followed by the flattened list of copied projects.
+func (d DotnetGenerator) ExplainProject(project generator.ProjectData) []string {
+	proj, ok := project.(Project)
+	if !ok {
+		return nil
+	}
+	var lines []string
+	var visit func(p Project, depth int, path []string)
+	visit = func(p Project, depth int, path []string) {
+		line := strings.Repeat("  ", depth) + p.GetRelativePath()
+		if p.TargetFramework != "" {
+			line += " (" + p.TargetFramework + ")"
+		}
+		if slices.Contains(path, p.Path) {
+			lines = append(lines, line+" [cycle]")
+			return
+		}
+		lines = append(lines, line)
+		for _, child := range p.ProjectReferences {
+			visit(child, depth+1, append(path, p.Path))
+		}
+	}
+	visit(proj, 0, nil)
+	all := proj.GetAllProjectReferences()
+	lines = append(lines, fmt.Sprintf("copied before restore (%d, from GetAllProjectReferences):", len(all)))
+	for _, p := range all {
+		lines = append(lines, "  "+p.GetRelativePath())
+	}
+	return lines
+}
+
 // Load resolves the target project (or the single .csproj inside the directory) and returns the project graph + additional context files.
-func (d DotnetGenerator) Load(projectPath, repoRoot string) (
+func (d DotnetGenerator) Load(log *slog.Logger, projectPath, repoRoot string) (
 	generator.ProjectData,
 	[]common.AdditionalFilePath,
 	error) {
@@ -76,31 +207,32 @@ func (d DotnetGenerator) Load(projectPath, repoRoot string) (
 			return nil, nil, fmt.Errorf("multiple .csproj found; specify one explicitly")
 		}
 		p = matches[0]
-		slog.Debug("resolved single project file in directory", "dir", projectPath, "file", p)
+		log.Debug("resolved single project file in directory", "dir", projectPath, "file", p)
 	}
 	if !strings.HasSuffix(strings.ToLower(p), ".csproj") {
 		return nil, nil, errors.New("path must be a .csproj file for dotnet")
 	}
-	slog.Debug("loading dotnet project", "path", p, "repoRoot", repoRoot)
-	proj, err := LoadProject(p, repoRoot)
+	log.Debug("loading dotnet project", "path", p, "repoRoot", repoRoot)
+	proj, err := LoadProject(log, p, repoRoot)
 	if err != nil {
 		return nil, nil, err
 	}
 	references := proj.GetAllProjectReferences()
-	slog.Debug("project graph loaded", "root", proj.Path, "projects", len(references))
-	additional, err := LoadProjectContextFromProject(proj, repoRoot)
+	log.Debug("project graph loaded", "root", proj.Path, "projects", len(references))
+	additional, err := LoadProjectContextFromProject(log, proj, repoRoot)
 	if err != nil {
 		return nil, nil, err
 	}
-	slog.Debug("additional context files discovered", "count", len(additional))
+	log.Debug("additional context files discovered", "count", len(additional))
 	return proj, additional, nil
 }
 
-// GenerateDockerfile renders the Dockerfile into dest using the discovered project + configuration.
+// GenerateDockerfile renders the Dockerfile into w using the discovered project + configuration.
 func (d DotnetGenerator) GenerateDockerfile(
+	log *slog.Logger,
 	project generator.ProjectData,
 	additional []common.AdditionalFilePath,
-	dest string,
+	w io.Writer,
 	cfg config.Config) error {
 	proj, ok := project.(Project)
 	if !ok {
@@ -109,44 +241,95 @@ func (d DotnetGenerator) GenerateDockerfile(
 
 	var baseImage, baseSdkImage string
 	if cfg.Base.Image == "" {
-		baseImage = "mcr.microsoft.com/dotnet/aspnet:${TARGET_DOTNET_VERSION}-alpine"
+		baseImage = defaultBaseImage
 	} else {
 		baseImage = cfg.Base.Image
 	}
 	if cfg.BaseBuild.Image == "" {
-		baseSdkImage = "mcr.microsoft.com/dotnet/sdk:${TARGET_DOTNET_VERSION}-alpine"
+		baseSdkImage = defaultSdkImage
 	} else {
 		baseSdkImage = cfg.BaseBuild.Image
 	}
 
-	sdkVersion := "9.0"
+	sdkVersion := defaultSdkVersion
 	if cfg.Dotnet.SdkVersion != "" {
 		sdkVersion = cfg.Dotnet.SdkVersion
 	}
-	slog.Debug("dotnet image selection", "runtime", baseImage, "sdk", baseSdkImage, "sdkVersion", sdkVersion, "additionalFiles", len(additional))
+	log.Debug("dotnet image selection", "runtime", baseImage, "sdk", baseSdkImage, "sdkVersion", sdkVersion, "additionalFiles", len(additional))
 
-	tmpl, err := template.New("dotnet-dockerfile").Parse(defaultTemplate)
-	if err != nil {
-		return err
-	}
-	f, err := os.Create(dest) // #nosec G304 - destination path intentionally created in working directory
+	final := finalConfig(proj, cfg)
+	stages, copies, err := common.ExtraStages(cfg.Stages, builtinStages, "final")
 	if err != nil {
 		return err
 	}
-	// Execute then close explicitly to surface close errors (errcheck compliance).
-	execErr := tmpl.Execute(f, TemplateContext{
+	return templates.Render(w, "dotnet-dockerfile", defaultTemplate, cfg, TemplateContext{
 		AdditionalFilePaths: additional,
 		Project:             proj,
 		Config:              cfg,
 		BaseImage:           baseImage,
 		BaseSdkImage:        baseSdkImage,
 		SdkVersion:          sdkVersion,
+		BaseInstructions:    baseInstructions(final),
+		FinalInstructions:   common.FinalInstructions(config.FinalConfig{Labels: final.Labels, Healthcheck: final.Healthcheck}),
+		Workdir:             final.Workdir,
+		Entrypoint:          common.ExecForm(final.Entrypoint),
+		Cmd:                 common.ExecForm(final.Cmd),
+		Stages:              stages,
+		FinalCopies:         copies,
 	})
-	closeErr := f.Close()
-	if execErr != nil {
-		return execErr
+}
+
+// Check warns when the health check probe is unlikely to exist in the runtime image.
+func (d DotnetGenerator) Check(project generator.ProjectData, cfg config.Config) []string {
+	proj, ok := project.(Project)
+	if !ok {
+		return nil
+	}
+	return common.FinalWarnings(finalConfig(proj, cfg), cfg.Base, defaultBaseImage)
+}
+
+// finalConfig returns cfg.Final with the generator defaults applied: port 8080, the locale
+// environment, /app as working directory, the project's dll as entrypoint and, when the project
+// graph references a health checks package, an HTTP probe.
+func finalConfig(proj Project, cfg config.Config) config.FinalConfig {
+	defaults := common.FinalDefaults{
+		Workdir:    defaultWorkdir,
+		Entrypoint: []string{"dotnet", proj.GetName() + ".dll"},
+		Ports:      []string{defaultPort},
+		Env:        defaultEnv,
+	}
+	if usesHealthChecks(proj) {
+		defaults.ProbePath = healthCheckPath
+	}
+	return common.ApplyFinalDefaults(cfg.Final, defaults)
+}
+
+// baseInstructions returns the EXPOSE and ENV instructions of final. The template renders them
+// in the base stage, inherited by the final one, so that a Dockerfile generated without final.*
+// keys is unchanged from the versions that did not have them.
+func baseInstructions(final config.FinalConfig) []string {
+	var out []string
+	if len(final.Ports) > 0 {
+		out = append(out, "EXPOSE "+strings.Join(final.Ports, " "))
+	}
+	if len(final.Env) > 0 {
+		out = append(out, common.KeyValueInstruction("ENV", final.Env, defaultEnvOrder...))
+	}
+	return out
+}
+
+// usesHealthChecks reports whether a project of the graph references a health checks package.
+func usesHealthChecks(proj Project) bool {
+	for _, p := range proj.GetAllProjectReferences() {
+		for _, ref := range p.PackageReferences {
+			for _, prefix := range healthCheckPackages {
+				if strings.HasPrefix(strings.ToLower(ref.Include), strings.ToLower(prefix)) {
+					return true
+				}
+			}
+		}
 	}
-	return closeErr
+	return false
 }
 
 func init() { generator.Register(DotnetGenerator{}) }
@@ -1,8 +1,11 @@
 package dotnet
 
 import (
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
@@ -13,7 +16,7 @@ func TestDotnetGenerator_MultipleCsprojError(t *testing.T) {
 	dir := t.TempDir()
 	_ = os.WriteFile(filepath.Join(dir, "A.csproj"), []byte("<Project></Project>"), 0o600)
 	_ = os.WriteFile(filepath.Join(dir, "B.csproj"), []byte("<Project></Project>"), 0o600)
-	_, _, err := g.Load(dir, dir)
+	_, _, err := g.Load(slog.Default(), dir, dir)
 	if err == nil {
 		t.Fatalf("expected error for multiple csproj files")
 	}
@@ -28,16 +31,15 @@ func TestDotnetGenerator_GenerateDefaultImages(t *testing.T) {
 		0o600); err != nil {
 		t.Fatalf("write: %v", err)
 	}
-	proj, additional, err := g.Load(projPath, dir)
+	proj, additional, err := g.Load(slog.Default(), projPath, dir)
 	if err != nil {
 		t.Fatalf("load: %v", err)
 	}
-	dest := filepath.Join(dir, "Dockerfile")
-	if err := g.GenerateDockerfile(proj, additional, dest, config.Default()); err != nil {
+	var b strings.Builder
+	if err := g.GenerateDockerfile(slog.Default(), proj, additional, &b, config.Default()); err != nil {
 		t.Fatalf("generate: %v", err)
 	}
-	data, _ := os.ReadFile(dest) // #nosec G304 - test reading generated file path
-	content := string(data)
+	content := b.String()
 	if !contains(content, "mcr.microsoft.com/dotnet/aspnet:${TARGET_DOTNET_VERSION}") {
 		t.Fatalf("expected default aspnet base image in output")
 	}
@@ -55,19 +57,18 @@ func TestDotnetGenerator_ConfigOverrides(t *testing.T) {
 		0o600); err != nil {
 		t.Fatalf("write: %v", err)
 	}
-	proj, additional, err := g.Load(projPath, dir)
+	proj, additional, err := g.Load(slog.Default(), projPath, dir)
 	if err != nil {
 		t.Fatalf("load: %v", err)
 	}
-	dest := filepath.Join(dir, "Dockerfile.override")
+	var b strings.Builder
 	cfg := config.Config{
 		Base: config.ImageConfig{Image: "customruntime:1"}, BaseBuild: config.ImageConfig{Image: "customsdk:1"},
 	}
-	if err := g.GenerateDockerfile(proj, additional, dest, cfg); err != nil {
+	if err := g.GenerateDockerfile(slog.Default(), proj, additional, &b, cfg); err != nil {
 		t.Fatalf("generate: %v", err)
 	}
-	data, _ := os.ReadFile(dest) // #nosec G304 - test reading generated file path
-	content := string(data)
+	content := b.String()
 	if !contains(content, "FROM customruntime:1 AS base") || !contains(content, "FROM customsdk:1 AS build") {
 		t.Fatalf("expected override images present, got: %s", content)
 	}
@@ -96,18 +97,17 @@ func TestDotnetGenerator_FinalRunCommands(t *testing.T) {
 		f := err
 		t.Fatalf("write: %v", f)
 	}
-	proj, additional, err := g.Load(projPath, dir)
+	proj, additional, err := g.Load(slog.Default(), projPath, dir)
 	if err != nil {
 		f := err
 		t.Fatalf("load: %v", f)
 	}
-	dest := filepath.Join(dir, "Dockerfile.finalrun")
+	var b strings.Builder
 	cfg := config.Config{Final: config.FinalConfig{Run: []string{"adduser -D testuser", "echo done"}}}
-	if err := g.GenerateDockerfile(proj, additional, dest, cfg); err != nil {
+	if err := g.GenerateDockerfile(slog.Default(), proj, additional, &b, cfg); err != nil {
 		t.Fatalf("generate: %v", err)
 	}
-	data, _ := os.ReadFile(dest) // #nosec G304 - test reading generated file path
-	content := string(data)
+	content := b.String()
 	if !contains(content, "RUN adduser -D testuser") || !contains(content, "RUN echo done") {
 		t.Fatalf("expected final run commands in dockerfile, got: %s", content)
 	}
@@ -118,6 +118,163 @@ func TestDotnetGenerator_FinalRunCommands(t *testing.T) {
 	}
 }
 
+func TestDotnetGenerator_FinalPortsEnvLabels(t *testing.T) {
+	g := DotnetGenerator{}
+	dir := t.TempDir()
+	projPath := filepath.Join(dir, "App.csproj")
+	if err := os.WriteFile(projPath, []byte(`<Project Sdk="Microsoft.NET.Sdk"></Project>`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	proj, additional, err := g.Load(slog.Default(), projPath, dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	render := func(cfg config.Config) string {
+		t.Helper()
+		var b strings.Builder
+		if err := g.GenerateDockerfile(slog.Default(), proj, additional, &b, cfg); err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+		return b.String()
+	}
+
+	defaults := render(config.Config{})
+	// Same placement and order as before final.* existed, so earlier Dockerfiles stay up to date.
+	if !strings.Contains(defaults, "AS base\nWORKDIR /app\nEXPOSE 8080\nENV \\\n"+
+		"    DOTNET_SYSTEM_GLOBALIZATION_INVARIANT=false \\\n    LC_ALL=en_US.UTF-8 \\\n    LANG=en_US.UTF-8\n") {
+		t.Fatalf("expected default EXPOSE and ENV in the base stage, got: %s", defaults)
+	}
+
+	custom := render(config.Config{Final: config.FinalConfig{
+		Ports:  []string{"5000"},
+		Env:    map[string]string{"LANG": "C.UTF-8", "ASPNETCORE_URLS": "http://+:5000"},
+		Labels: map[string]string{"org.opencontainers.image.description": "My app"},
+	}})
+	for _, want := range []string{
+		"EXPOSE 5000\n",
+		"    LANG=C.UTF-8 \\\n    ASPNETCORE_URLS=http://+:5000\n",
+		"LABEL \\\n    org.opencontainers.image.description=\"My app\"\n",
+	} {
+		if !strings.Contains(custom, want) {
+			t.Fatalf("expected %q in dockerfile, got: %s", want, custom)
+		}
+	}
+	if strings.Contains(custom, "EXPOSE 8080") {
+		t.Fatalf("final.ports must replace the default port: %s", custom)
+	}
+}
+
+func TestDotnetGenerator_EntrypointCmdWorkdir(t *testing.T) {
+	g := DotnetGenerator{}
+	dir := t.TempDir()
+	projPath := filepath.Join(dir, "App.csproj")
+	if err := os.WriteFile(projPath, []byte(`<Project Sdk="Microsoft.NET.Sdk"></Project>`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	proj, additional, err := g.Load(slog.Default(), projPath, dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	var b strings.Builder
+	if err := g.GenerateDockerfile(slog.Default(), proj, additional, &b, config.Config{}); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if !strings.Contains(b.String(), "FROM base AS final\nWORKDIR /app\n") ||
+		!strings.HasSuffix(b.String(), "ENTRYPOINT [\"dotnet\", \"App.dll\"]\n") {
+		t.Fatalf("expected default WORKDIR and ENTRYPOINT, got: %s", b.String())
+	}
+
+	b.Reset()
+	cfg := config.Config{Final: config.FinalConfig{
+		Workdir:    "/opt/app",
+		Entrypoint: []string{"/opt/app/entrypoint.sh"},
+		Cmd:        []string{"dotnet", "App.dll", "--urls", "http://+:8080"},
+	}}
+	if err := g.GenerateDockerfile(slog.Default(), proj, additional, &b, cfg); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	want := "ENTRYPOINT [\"/opt/app/entrypoint.sh\"]\nCMD [\"dotnet\", \"App.dll\", \"--urls\", \"http://+:8080\"]\n"
+	if !strings.Contains(b.String(), "FROM base AS final\nWORKDIR /opt/app\n") || !strings.HasSuffix(b.String(), want) {
+		t.Fatalf("expected configured WORKDIR, ENTRYPOINT and CMD, got: %s", b.String())
+	}
+}
+
+func TestDotnetGenerator_ExtraStages(t *testing.T) {
+	g := DotnetGenerator{}
+	dir := t.TempDir()
+	projPath := filepath.Join(dir, "App.csproj")
+	if err := os.WriteFile(projPath, []byte(`<Project Sdk="Microsoft.NET.Sdk"></Project>`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	proj, additional, err := g.Load(slog.Default(), projPath, dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	cfg := config.Config{Stages: map[string]config.Stage{
+		"migrations": {From: "build", Instructions: []string{"RUN dotnet ef migrations bundle -o /out/efbundle"}},
+		"tools": {From: "publish", Instructions: []string{"RUN dotnet tool install dotnet-counters --tool-path /tools"},
+			CopyIntoFinal: map[string]string{"/tools": "/tools"}},
+	}}
+	var b strings.Builder
+	if err := g.GenerateDockerfile(slog.Default(), proj, additional, &b, cfg); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	out := b.String()
+	if !strings.Contains(out, "/p:UseAppHost=false\n\nFROM build AS migrations\n"+
+		"RUN dotnet ef migrations bundle -o /out/efbundle\n\nFROM publish AS tools\n") ||
+		!strings.Contains(out, "--tool-path /tools\n\nFROM base AS final\n") ||
+		!strings.Contains(out, "/app/publish .\nCOPY --from=tools /tools /tools\nENTRYPOINT") {
+		t.Fatalf("expected extra stages before final, got: %s", out)
+	}
+
+	cfg.Stages = map[string]config.Stage{"publish": {From: "build"}}
+	if err := g.GenerateDockerfile(slog.Default(), proj, additional, &b, cfg); err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Fatalf("expected duplicate stage error, got %v", err)
+	}
+}
+
+func TestDotnetGenerator_InferredHealthcheck(t *testing.T) {
+	g := DotnetGenerator{}
+	dir := t.TempDir()
+	projPath := filepath.Join(dir, "Api.csproj")
+	csproj := `<Project Sdk="Microsoft.NET.Sdk.Web"><ItemGroup>` +
+		`<PackageReference Include="AspNetCore.HealthChecks.NpgSql" Version="8.0.0" /></ItemGroup></Project>`
+	if err := os.WriteFile(projPath, []byte(csproj), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	proj, additional, err := g.Load(slog.Default(), projPath, dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	render := func(cfg config.Config) string {
+		t.Helper()
+		var b strings.Builder
+		if err := g.GenerateDockerfile(slog.Default(), proj, additional, &b, cfg); err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+		return b.String()
+	}
+
+	inferred := render(config.Config{Final: config.FinalConfig{Healthcheck: config.HealthcheckConfig{Interval: "1m"}}})
+	want := "HEALTHCHECK --interval=1m --timeout=5s --start-period=10s --retries=3 \\\n" +
+		"    CMD wget --no-verbose --tries=1 --spider http://localhost:8080/health || exit 1\n"
+	if !strings.Contains(inferred, want) {
+		t.Fatalf("expected inferred probe %q, got: %s", want, inferred)
+	}
+	disabled := render(config.Config{Final: config.FinalConfig{Healthcheck: config.HealthcheckConfig{Command: "NONE"}}})
+	if !strings.Contains(disabled, "HEALTHCHECK NONE\n") {
+		t.Fatalf("expected HEALTHCHECK NONE, got: %s", disabled)
+	}
+
+	if w := g.Check(proj, config.Config{}); len(w) != 0 {
+		t.Fatalf("expected no warning for the alpine default image, got %v", w)
+	}
+	chiseled := config.Config{Base: config.ImageConfig{Image: "mcr.microsoft.com/dotnet/aspnet:9.0-noble-chiseled"}}
+	if w := g.Check(proj, chiseled); len(w) != 1 || !strings.Contains(w[0], "runs wget") {
+		t.Fatalf("expected a missing wget warning, got %v", w)
+	}
+}
+
 // index returns the index of sub in s or -1 if absent (avoids importing strings)
 func index(s, sub string) int {
 	if len(sub) == 0 {
@@ -140,20 +297,59 @@ func TestDotnetGenerator_CustomSdkVersion(t *testing.T) {
 		0o600); err != nil {
 		t.Fatalf("write: %v", err)
 	}
-	proj, additional, err := g.Load(projPath, dir)
+	proj, additional, err := g.Load(slog.Default(), projPath, dir)
 	if err != nil {
 		t.Fatalf("load: %v", err)
 	}
-	dest := filepath.Join(dir, "Dockerfile.sdkversion")
+	var b strings.Builder
 	cfg := config.Config{
 		Dotnet: config.DotnetConfig{SdkVersion: "8.0"},
 	}
-	if err := g.GenerateDockerfile(proj, additional, dest, cfg); err != nil {
+	if err := g.GenerateDockerfile(slog.Default(), proj, additional, &b, cfg); err != nil {
 		t.Fatalf("generate: %v", err)
 	}
-	data, _ := os.ReadFile(dest) // #nosec G304 - test reading generated file path
-	content := string(data)
+	content := b.String()
 	if !contains(content, "ARG TARGET_DOTNET_VERSION=8.0") {
 		t.Fatalf("expected TARGET_DOTNET_VERSION=8.0 in dockerfile, got: %s", content)
 	}
 }
+
+// TestDotnetGenerator_ConcurrentUse exercises the concurrency contract (run with -race).
+func TestDotnetGenerator_ConcurrentUse(t *testing.T) {
+	g := DotnetGenerator{}
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "Directory.Build.props"), `<Project/>`)
+	writeFile(t, filepath.Join(root, "nuget.config"), `<configuration/>`)
+	var projects []string
+	for _, name := range []string{"A", "B", "C", "D"} {
+		dir := filepath.Join(root, name)
+		if err := os.Mkdir(dir, 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		writeFile(t, filepath.Join(dir, name+".csproj"), `<Project><ItemGroup><ProjectReference Include="../Shared/Shared.csproj" /></ItemGroup></Project>`)
+		projects = append(projects, dir)
+	}
+	if err := os.Mkdir(filepath.Join(root, "Shared"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, filepath.Join(root, "Shared", "Shared.csproj"), `<Project/>`)
+
+	var wg sync.WaitGroup
+	for _, dir := range projects {
+		wg.Go(func() {
+			proj, additional, err := g.Load(slog.Default(), dir, root)
+			if err != nil {
+				t.Errorf("load %s: %v", dir, err)
+				return
+			}
+			if len(additional) != 2 {
+				t.Errorf("expected 2 additional files for %s, got %d", dir, len(additional))
+			}
+			var b strings.Builder
+			if err := g.GenerateDockerfile(slog.Default(), proj, additional, &b, config.Default()); err != nil {
+				t.Errorf("generate %s: %v", dir, err)
+			}
+		})
+	}
+	wg.Wait()
+}
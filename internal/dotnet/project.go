@@ -42,6 +42,7 @@ type propertyGroupXML struct{ TargetFramework, OutputType, AssemblyName string }
 type Project struct {
 	RootPath          string
 	Path              string
+	TargetFramework   string
 	ProjectReferences []Project
 	PackageReferences []PackageReference
 }
@@ -97,6 +98,24 @@ func (p Project) GetAllProjectReferences() []Project {
 // GetProjectReferences returns the direct project references.
 func (p Project) GetProjectReferences() []Project { return p.ProjectReferences }
 
+// SdkVersion returns the numeric .NET version of the TargetFramework (net8.0 → 8.0,
+// netcoreapp3.1 → 3.1, net8.0-windows → 8.0) or an empty string if it cannot be derived.
+func (p Project) SdkVersion() string {
+	tfm := strings.ToLower(p.TargetFramework)
+	if i := strings.Index(tfm, "-"); i >= 0 {
+		tfm = tfm[:i]
+	}
+	for _, prefix := range []string{"netcoreapp", "net"} {
+		if v, ok := strings.CutPrefix(tfm, prefix); ok {
+			if v != "" && v[0] >= '0' && v[0] <= '9' && strings.Contains(v, ".") {
+				return v
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
 // PackageReference represents a NuGet package reference (Include + Version).
 type PackageReference struct{ Include, Version string }
 
@@ -105,7 +124,7 @@ var (
 	errCircularRef    = fmt.Errorf("circular reference")
 )
 
-func innerLoadProject(path string, isMain bool, rootPath string, pathLoaded []string) (Project, error) {
+func innerLoadProject(log *slog.Logger, path string, isMain bool, rootPath string, pathLoaded []string) (Project, error) {
 	for _, loadedPath := range pathLoaded {
 		if loadedPath == path {
 			return Project{}, errCircularRef
@@ -124,7 +143,7 @@ func innerLoadProject(path string, isMain bool, rootPath string, pathLoaded []st
 	}(path)
 	if err != nil {
 		if !isMain {
-			slog.Warn("Cannot open file. Skipped", "path", path, "err", err)
+			log.Warn("Cannot open file. Skipped", "path", path, "err", err)
 			return Project{}, errMissingProject
 		}
 		return Project{}, err
@@ -142,7 +161,7 @@ func innerLoadProject(path string, isMain bool, rootPath string, pathLoaded []st
 		func(ig itemGroupXML) bool { return len(ig.ProjectReference) > 0 }),
 		func(ig itemGroupXML) []projectReferenceXML { return ig.ProjectReference })
 	// resolve and load all child project references (supports wildcards)
-	references, refErr := loadProjectReferences(filepath.Dir(path), projectReferences, rootPath, append(pathLoaded, path))
+	references, refErr := loadProjectReferences(log, filepath.Dir(path), projectReferences, rootPath, append(pathLoaded, path))
 	if refErr != nil {
 		return Project{}, refErr
 	}
@@ -157,21 +176,31 @@ func innerLoadProject(path string, isMain bool, rootPath string, pathLoaded []st
 		}
 		packages = append(packages, PackageReference{Include: pr.Include, Version: v})
 	}
-	return Project{RootPath: rootPath, Path: path, ProjectReferences: references, PackageReferences: packages}, nil
+	var targetFramework string
+	for _, pg := range px.PropertyGroups {
+		if pg.TargetFramework != "" {
+			targetFramework = strings.TrimSpace(pg.TargetFramework)
+			break
+		}
+	}
+	return Project{
+		RootPath: rootPath, Path: path, TargetFramework: targetFramework,
+		ProjectReferences: references, PackageReferences: packages,
+	}, nil
 }
 
 // LoadProject loads a root .csproj and recursively its transitive project references.
-func LoadProject(path, rootPath string) (Project, error) {
-	return innerLoadProject(path, true, rootPath, []string{})
+func LoadProject(log *slog.Logger, path, rootPath string) (Project, error) {
+	return innerLoadProject(log, path, true, rootPath, []string{})
 }
 
 // loadProjectReferences resolves project reference includes (supports wildcards) and loads each child project.
-func loadProjectReferences(baseDir string, projectReferences []projectReferenceXML, rootPath string, pathLoaded []string) ([]Project, error) {
+func loadProjectReferences(log *slog.Logger, baseDir string, projectReferences []projectReferenceXML, rootPath string, pathLoaded []string) ([]Project, error) {
 	var references []Project
 	for _, pr := range projectReferences {
 		childPaths := resolveChildPaths(baseDir, pr.Include)
 		for _, cp := range childPaths {
-			child, prjErr := innerLoadProject(cp, false, rootPath, pathLoaded)
+			child, prjErr := innerLoadProject(log, cp, false, rootPath, pathLoaded)
 			if prjErr != nil {
 				if errors.Is(prjErr, errMissingProject) {
 					continue
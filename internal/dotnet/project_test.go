@@ -2,6 +2,7 @@ package dotnet
 
 import (
 	"errors"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
@@ -45,7 +46,7 @@ func TestLoadProject_WithReferencesAndPackages(t *testing.T) {
 	mainContent = replace(mainContent, "{{PACKAGES}}", `<PackageReference Include="Serilog" Version="4.0.0"/>`)
 	write(t, mainPath, mainContent)
 
-	proj, err := LoadProject(mainPath, root)
+	proj, err := LoadProject(slog.Default(), mainPath, root)
 	if err != nil {
 		t.Fatalf("load project: %v", err)
 	}
@@ -82,7 +83,7 @@ func TestLoadProject_CircularReference(t *testing.T) {
 	bContent = replace(bContent, "{{REFERENCES}}", `<ProjectReference Include="../A/A.csproj" />`)
 	write(t, pathB, bContent)
 
-	_, err := LoadProject(pathA, root)
+	_, err := LoadProject(slog.Default(), pathA, root)
 	if !errors.Is(err, errCircularRef) {
 		// errCircularRef should bubble up
 		if err == nil {
@@ -110,3 +111,19 @@ func stringReplaceAll(s, old, newVal string) string {
 	}
 	return string(out)
 }
+
+func TestProjectSdkVersion(t *testing.T) {
+	cases := map[string]string{
+		"net8.0":         "8.0",
+		"net9.0-windows": "9.0",
+		"netcoreapp3.1":  "3.1",
+		"netstandard2.0": "",
+		"net48":          "",
+		"":               "",
+	}
+	for tfm, want := range cases {
+		if got := (Project{TargetFramework: tfm}).SdkVersion(); got != want {
+			t.Fatalf("SdkVersion(%q) = %q, want %q", tfm, got, want)
+		}
+	}
+}
@@ -1,8 +1,18 @@
 // Package generator defines the extensible registry and interfaces for language-specific Dockerfile generators.
+//
+// Concurrency: generators are registered from package init functions only; after init the
+// registry is read-only and Get / All may be called from any goroutine. Generator
+// implementations must be safe for concurrent use: Detect, Load and GenerateDockerfile are
+// called in parallel for different projects when several projects are processed at once,
+// so implementations must not keep mutable state outside of a single call. Load and
+// GenerateDockerfile log to the logger they are given, which buffers the records of one project,
+// rather than to the default slog logger.
 package generator
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
 
 	"github.com/n2jsoft-public-org/dockerfile-generator/internal/common"
 	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
@@ -12,21 +22,91 @@ import (
 type ProjectData interface{}
 
 // Generator describes a language-specific Dockerfile generator.
+// Implementations must be stateless (or otherwise safe for concurrent use); see the package docs.
 type Generator interface {
 	Name() string
 	Detect(path string) (bool, error)
-	Load(projectPath, repoRoot string) (ProjectData, []common.AdditionalFilePath, error)
+	Load(log *slog.Logger, projectPath, repoRoot string) (ProjectData, []common.AdditionalFilePath, error)
+	// GenerateDockerfile renders the Dockerfile into w; callers decide where the bytes go.
 	GenerateDockerfile(
+		log *slog.Logger,
 		project ProjectData,
 		additional []common.AdditionalFilePath,
-		dest string,
+		w io.Writer,
 		cfg config.Config) error
 }
 
+// ImageDefaults lists the images a generator uses when the configuration does not override them.
+type ImageDefaults struct {
+	Base      string
+	BaseBuild string
+}
+
+// Defaulter is optionally implemented by generators to expose their default images.
+type Defaulter interface {
+	DefaultImages() ImageDefaults
+}
+
+// ConfigSuggester is optionally implemented by generators that can prefill a configuration
+// from a loaded project (used by the init command).
+type ConfigSuggester interface {
+	SuggestConfig(project ProjectData) config.Config
+}
+
+// Description is human-facing metadata about a generator, listed by the languages command
+// and used by shell completion.
+type Description struct {
+	Summary      string
+	Detects      []string // what Detect looks for
+	ProjectFiles []string // filepath.Match patterns naming a project file (offered by shell completion)
+	ConfigKeys   []string // dotted .dockerbuild keys the generator honors
+}
+
+// Describer is optionally implemented by generators to describe themselves.
+type Describer interface {
+	Describe() Description
+}
+
+// InputLister is optionally implemented by generators to list the project definition files a
+// generation depends on (for example every .csproj in the graph, or go.mod / go.sum). Context
+// files returned by Load and the configuration file are tracked by the caller.
+type InputLister interface {
+	ProjectInputs(project ProjectData) []string
+}
+
+// InputMatcher is optionally implemented by generators that find context files by searching
+// directories (for example every Directory.Build.props up to the repository root). It reports
+// whether a file at path would be picked up for project, so that creating or deleting one affects
+// the project although the path is not among its current inputs.
+type InputMatcher interface {
+	MatchesInput(project ProjectData, path string) bool
+}
+
+// Explainer is optionally implemented by generators to describe a loaded project
+// (dependency graph, module information) for the explain command. Lines are indented
+// with two spaces per nesting level.
+type Explainer interface {
+	ExplainProject(project ProjectData) []string
+}
+
+// Checker is optionally implemented by generators to report problems that do not prevent
+// generation, such as a health check probe the runtime image cannot run. Each returned string is
+// one warning.
+type Checker interface {
+	Check(project ProjectData, cfg config.Config) []string
+}
+
+// Templater is optionally implemented by generators that render an embedded text/template, so it
+// can be exported as the starting point of a custom template and compared with one.
+type Templater interface {
+	Template() string
+}
+
 var registry = map[string]Generator{}
 var ordered []Generator
 
 // Register adds a generator implementation to the registry.
+// It must only be called during package initialization.
 func Register(g Generator) {
 	if g == nil {
 		panic("nil generator")
@@ -57,3 +137,12 @@ func MustGet(name string) Generator {
 
 // All returns generators in registration order.
 func All() []Generator { return ordered }
+
+// Names returns the registered generator names in registration order.
+func Names() []string {
+	names := make([]string, 0, len(ordered))
+	for _, g := range ordered {
+		names = append(names, g.Name())
+	}
+	return names
+}
@@ -1,6 +1,9 @@
 package generator
 
 import (
+	"io"
+	"log/slog"
+	"sync"
 	"testing"
 
 	"github.com/n2jsoft-public-org/dockerfile-generator/internal/common"
@@ -11,10 +14,10 @@ type mockGen struct{ name string }
 
 func (m mockGen) Name() string                { return m.name }
 func (m mockGen) Detect(string) (bool, error) { return false, nil }
-func (m mockGen) Load(string, string) (ProjectData, []common.AdditionalFilePath, error) {
+func (m mockGen) Load(*slog.Logger, string, string) (ProjectData, []common.AdditionalFilePath, error) {
 	return nil, nil, nil
 }
-func (m mockGen) GenerateDockerfile(ProjectData, []common.AdditionalFilePath, string, config.Config) error {
+func (m mockGen) GenerateDockerfile(*slog.Logger, ProjectData, []common.AdditionalFilePath, io.Writer, config.Config) error {
 	return nil
 }
 
@@ -61,3 +64,38 @@ func TestRegistryBasic(t *testing.T) {
 
 	mustPanic(t, func() { MustGet("missing") }, "MustGet missing should panic")
 }
+
+func TestRegistryConcurrentReads(t *testing.T) {
+	origReg := registry
+	origOrdered := ordered
+	registry = map[string]Generator{}
+	ordered = nil
+	defer func() { registry = origReg; ordered = origOrdered }()
+	Register(mockGen{name: "lang1"})
+	Register(mockGen{name: "lang2"})
+
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Go(func() {
+			for _, g := range All() {
+				if got, ok := Get(g.Name()); !ok || got.Name() != g.Name() {
+					t.Errorf("Get(%q) returned %v, %v", g.Name(), got, ok)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestRegistryNames(t *testing.T) {
+	origReg := registry
+	origOrdered := ordered
+	registry = map[string]Generator{}
+	ordered = nil
+	defer func() { registry = origReg; ordered = origOrdered }()
+	Register(mockGen{name: "b"})
+	Register(mockGen{name: "a"})
+	if got := Names(); len(got) != 2 || got[0] != "b" || got[1] != "a" {
+		t.Fatalf("expected registration order [b a], got %v", got)
+	}
+}
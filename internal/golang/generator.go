@@ -4,25 +4,52 @@ package golang
 import (
 	_ "embed"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
-	"text/template"
 
 	"github.com/n2jsoft-public-org/dockerfile-generator/internal/common"
 	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
 	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/templates"
 )
 
 //go:embed dockerfile.tmpl
 var goTemplate string
 
+const (
+	defaultBuildImage   = "golang:${GO_VERSION}-alpine"
+	defaultRuntimeImage = "alpine:3.19"
+	healthCheckPath     = "/health"
+	defaultWorkdir      = "/app"
+)
+
+// defaultEntrypoint runs the binary copied into the working directory.
+var defaultEntrypoint = []string{"./app"}
+
+// builtinStages are the stages of the embedded template before the final one; extra stages
+// (the stages config key) are placed after them.
+var builtinStages = []string{"build"}
+
+// healthCheckModules are the health check libraries whose presence in go.mod (including major
+// version suffixes and subpackages) adds an HTTP probe of healthCheckPath unless
+// final.healthcheck.command is set.
+var healthCheckModules = []string{
+	"github.com/alexliesenfeld/health",
+	"github.com/hellofresh/health-go",
+	"github.com/heptiolabs/healthcheck",
+	"github.com/InVisionApp/go-health",
+	"github.com/etherlabsio/healthcheck",
+}
+
 // GoProject describes a Go module root (directory containing go.mod).
 type GoProject struct {
 	RootPath string
 	Path     string // directory containing go.mod
 	Name     string
+	Requires []string // module paths listed in go.mod require directives
 }
 
 // goTemplateContext is the template data for Go Dockerfile generation.
@@ -33,6 +60,14 @@ type goTemplateContext struct {
 	RuntimeImage    string
 	BuildPackages   []string
 	RuntimePackages []string
+	// FinalInstructions are the EXPOSE, ENV, LABEL and HEALTHCHECK instructions of the final stage.
+	FinalInstructions []string
+	Workdir           string // WORKDIR of the final stage
+	Entrypoint        string // ENTRYPOINT in exec form
+	Cmd               string // CMD in exec form, empty when not configured
+	// Stages are the extra stages of the stages config key, in dependency order.
+	Stages      []string
+	FinalCopies []string // COPY --from instructions of the final stage for the extra stages
 }
 
 // GoGenerator implements generator.Generator for Go projects.
@@ -49,21 +84,65 @@ func (g GoGenerator) Detect(path string) (bool, error) {
 	}
 	if info.IsDir() {
 		_, err = os.Stat(filepath.Join(path, "go.mod"))
-		if err == nil {
-			slog.Debug("go project detected (directory contains go.mod)", "path", path)
-			return true, nil
-		}
-		return false, nil
+		return err == nil, nil
 	}
 	if strings.HasSuffix(path, "go.mod") {
-		slog.Debug("go project detected (go.mod file)", "path", path)
 		return true, nil
 	}
 	return false, nil
 }
 
+// Describe returns the metadata listed by the languages command.
+func (g GoGenerator) Describe() generator.Description {
+	return generator.Description{
+		Summary:      "Go modules: cached module download and static build copied into a small runtime image",
+		Detects:      []string{"a go.mod file", "a directory containing go.mod"},
+		ProjectFiles: []string{"go.mod"},
+		ConfigKeys: []string{
+			"language", "template", "hooks", "stages", "base.image", "base.packages", "base-build.image",
+			"final.ports", "final.env", "final.labels", "final.healthcheck",
+			"final.workdir", "final.entrypoint", "final.cmd",
+		},
+	}
+}
+
+// DefaultImages returns the build and runtime images used when the config does not override them.
+func (g GoGenerator) DefaultImages() generator.ImageDefaults {
+	return generator.ImageDefaults{Base: defaultRuntimeImage, BaseBuild: defaultBuildImage}
+}
+
+// Template returns the embedded Dockerfile template.
+func (g GoGenerator) Template() string { return goTemplate }
+
+// SuggestConfig returns a configuration prefilled with the default images.
+func (g GoGenerator) SuggestConfig(generator.ProjectData) config.Config {
+	return config.Config{
+		Language:  config.LanguageGo,
+		Base:      config.ImageConfig{Image: defaultRuntimeImage},
+		BaseBuild: config.ImageConfig{Image: defaultBuildImage},
+	}
+}
+
+// ProjectInputs returns the module's go.mod and go.sum.
+func (g GoGenerator) ProjectInputs(project generator.ProjectData) []string {
+	proj, ok := project.(GoProject)
+	if !ok {
+		return nil
+	}
+	return []string{filepath.Join(proj.Path, "go.mod"), filepath.Join(proj.Path, "go.sum")}
+}
+
+// ExplainProject describes the module that will be built.
+func (g GoGenerator) ExplainProject(project generator.ProjectData) []string {
+	proj, ok := project.(GoProject)
+	if !ok {
+		return nil
+	}
+	return []string{"module " + proj.Name + " at " + proj.Path}
+}
+
 // Load gathers basic module information and returns a GoProject.
-func (g GoGenerator) Load(projectPath, repoRoot string) (generator.ProjectData, []common.AdditionalFilePath, error) {
+func (g GoGenerator) Load(log *slog.Logger, projectPath, repoRoot string) (generator.ProjectData, []common.AdditionalFilePath, error) {
 	p := projectPath
 	info, err := os.Stat(p)
 	if err != nil {
@@ -81,58 +160,103 @@ func (g GoGenerator) Load(projectPath, repoRoot string) (generator.ProjectData,
 	if err != nil {
 		return nil, nil, err
 	}
-	slog.Debug("reading go module file", "path", modPath, "bytes", len(modData))
+	log.Debug("reading go module file", "path", modPath, "bytes", len(modData))
 	name := filepath.Base(p)
+	var requires []string
+	inRequireBlock := false
 	for _, l := range strings.Split(string(modData), "\n") {
 		l = strings.TrimSpace(l)
-		if strings.HasPrefix(l, "module ") {
+		if i := strings.Index(l, "//"); i >= 0 {
+			l = strings.TrimSpace(l[:i])
+		}
+		switch {
+		case strings.HasPrefix(l, "module "):
 			parsed := filepath.Base(strings.TrimSpace(strings.TrimPrefix(l, "module ")))
 			name = parsed
-			slog.Debug("parsed module name", "module", parsed)
+			log.Debug("parsed module name", "module", parsed)
+		case l == "require (":
+			inRequireBlock = true
+		case inRequireBlock && l == ")":
+			inRequireBlock = false
+		case inRequireBlock || strings.HasPrefix(l, "require "):
+			if fields := strings.Fields(strings.TrimPrefix(l, "require ")); len(fields) > 0 {
+				requires = append(requires, fields[0])
+			}
 		}
 	}
-	proj := GoProject{RootPath: repoRoot, Path: p, Name: name}
-	slog.Debug("go project loaded", "module", name, "path", p)
+	proj := GoProject{RootPath: repoRoot, Path: p, Name: name, Requires: requires}
+	log.Debug("go project loaded", "module", name, "path", p)
 	return proj, nil, nil
 }
 
-// GenerateDockerfile produces a Dockerfile at dest for the given project.
+// GenerateDockerfile renders a Dockerfile for the given project into w.
 func (g GoGenerator) GenerateDockerfile(
+	log *slog.Logger,
 	project generator.ProjectData,
 	additional []common.AdditionalFilePath,
-	dest string,
+	w io.Writer,
 	cfg config.Config) error {
 	proj, ok := project.(GoProject)
 	if !ok {
 		return fmt.Errorf("invalid project type for go generator")
 	}
-	buildImage := "golang:${GO_VERSION}-alpine"
-	runtimeImage := "alpine:3.19"
+	buildImage := defaultBuildImage
+	runtimeImage := defaultRuntimeImage
 	if cfg.BaseBuild.Image != "" {
 		buildImage = cfg.BaseBuild.Image
 	}
 	if cfg.Base.Image != "" {
 		runtimeImage = cfg.Base.Image
 	}
-	slog.Debug("go image selection", "build", buildImage, "runtime", runtimeImage, "additionalFiles", len(additional))
+	log.Debug("go image selection", "build", buildImage, "runtime", runtimeImage, "additionalFiles", len(additional))
+	final := finalConfig(proj, cfg)
+	stages, copies, err := common.ExtraStages(cfg.Stages, builtinStages, "final")
+	if err != nil {
+		return err
+	}
 	ctx := goTemplateContext{
 		Project: proj, Config: cfg, BuildImage: buildImage, RuntimeImage: runtimeImage,
 		BuildPackages: cfg.BaseBuild.Packages, RuntimePackages: cfg.Base.Packages,
+		FinalInstructions: common.FinalInstructions(final),
+		Workdir:           final.Workdir,
+		Entrypoint:        common.ExecForm(final.Entrypoint),
+		Cmd:               common.ExecForm(final.Cmd),
+		Stages:            stages,
+		FinalCopies:       copies,
 	}
-	tmpl, err := template.New("go-dockerfile").Parse(goTemplate)
-	if err != nil {
-		return err
+	return templates.Render(w, "go-dockerfile", goTemplate, cfg, ctx)
+}
+
+// Check warns when the health check probe is unlikely to exist in the runtime image.
+func (g GoGenerator) Check(project generator.ProjectData, cfg config.Config) []string {
+	proj, ok := project.(GoProject)
+	if !ok {
+		return nil
 	}
-	f, err := os.Create(dest) // #nosec G304 - controlled output path
-	if err != nil {
-		return err
+	return common.FinalWarnings(finalConfig(proj, cfg), cfg.Base, defaultRuntimeImage)
+}
+
+// finalConfig returns cfg.Final with the generator defaults applied: /app as working directory,
+// ./app as entrypoint and an HTTP probe when the module requires a health check library and no
+// command is configured.
+func finalConfig(proj GoProject, cfg config.Config) config.FinalConfig {
+	defaults := common.FinalDefaults{Workdir: defaultWorkdir, Entrypoint: defaultEntrypoint}
+	if usesHealthChecks(proj) {
+		defaults.ProbePath = healthCheckPath
 	}
-	execErr := tmpl.Execute(f, ctx)
-	closeErr := f.Close()
-	if execErr != nil {
-		return execErr
+	return common.ApplyFinalDefaults(cfg.Final, defaults)
+}
+
+// usesHealthChecks reports whether go.mod requires one of healthCheckModules.
+func usesHealthChecks(proj GoProject) bool {
+	for _, req := range proj.Requires {
+		for _, mod := range healthCheckModules {
+			if rest, ok := strings.CutPrefix(req, mod); ok && (rest == "" || rest[0] == '/') {
+				return true
+			}
+		}
 	}
-	return closeErr
+	return false
 }
 
 func init() { generator.Register(GoGenerator{}) }
@@ -1,9 +1,14 @@
 package golang
 
 import (
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
@@ -16,28 +21,27 @@ func TestGoGenerator_ConfigOverrides(t *testing.T) {
 	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0o600); err != nil {
 		t.Fatalf("write mod: %v", err)
 	}
-	proj, additional, err := g.Load(dir, dir)
+	proj, additional, err := g.Load(slog.Default(), dir, dir)
 	if err != nil {
 		t.Fatalf("load: %v", err)
 	}
 	if len(additional) != 0 {
 		t.Fatalf("expected no additional files")
 	}
-	dest := filepath.Join(dir, "Dockerfile")
+	var b strings.Builder
 	cfg := config.Config{
-		Base:      config.ImageConfig{Image: "alpine:3.20", Packages: []string{"ca-certificates"}},
+		Base:      config.ImageConfig{Image: "alpine:3.20", Packages: []string{"ca-certificates", "tzdata"}},
 		BaseBuild: config.ImageConfig{Image: "golang:1.24-alpine", Packages: []string{"build-base"}},
 	}
-	if err := g.GenerateDockerfile(proj, nil, dest, cfg); err != nil {
+	if err := g.GenerateDockerfile(slog.Default(), proj, nil, &b, cfg); err != nil {
 		t.Fatalf("generate: %v", err)
 	}
-	data, _ := os.ReadFile(dest) // #nosec G304 - test reading generated file
-	content := string(data)
+	content := b.String()
 	if !strings.Contains(content, "golang:1.24-alpine") || !strings.Contains(content, "alpine:3.20") {
 		t.Fatalf("expected overridden images, got: %s", content)
 	}
-	if !strings.Contains(content, "ca-certificates") { // simplified
-		t.Fatalf("expected runtime package in Dockerfile: %s", content)
+	if !strings.Contains(content, "RUN apk add --no-cache \\\n    ca-certificates \\\n    tzdata\n") {
+		t.Fatalf("expected one runtime package per line in Dockerfile: %s", content)
 	}
 	// build-base should NOT appear (current template ignores build-stage packages)
 	if strings.Contains(content, "build-base") {
@@ -45,6 +49,137 @@ func TestGoGenerator_ConfigOverrides(t *testing.T) {
 	}
 }
 
+func TestGoGenerator_FinalPortsEnvLabels(t *testing.T) {
+	g := GoGenerator{}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0o600); err != nil {
+		t.Fatalf("write mod: %v", err)
+	}
+	proj, _, err := g.Load(slog.Default(), dir, dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	var b strings.Builder
+	if err := g.GenerateDockerfile(slog.Default(), proj, nil, &b, config.Config{}); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if strings.Contains(b.String(), "EXPOSE") || strings.Contains(b.String(), "ENV") {
+		t.Fatalf("expected no EXPOSE or ENV by default, got: %s", b.String())
+	}
+
+	b.Reset()
+	cfg := config.Config{Final: config.FinalConfig{
+		Ports:  []string{"8080", "9090/udp"},
+		Env:    map[string]string{"PORT": "8080"},
+		Labels: map[string]string{"maintainer": "team <team@example.com>"},
+	}}
+	if err := g.GenerateDockerfile(slog.Default(), proj, nil, &b, cfg); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	want := "EXPOSE 8080 9090/udp\nENV \\\n    PORT=8080\nLABEL \\\n    maintainer=\"team <team@example.com>\"\nCOPY --from=build"
+	if !strings.Contains(b.String(), want) {
+		t.Fatalf("expected final stage instructions before COPY, got: %s", b.String())
+	}
+}
+
+func TestGoGenerator_InferredHealthcheck(t *testing.T) {
+	g := GoGenerator{}
+	dir := t.TempDir()
+	mod := "module example.com/app\n\ngo 1.23\n\nrequire (\n\tgithub.com/hellofresh/health-go/v5 v5.5.3\n" +
+		"\tgolang.org/x/sync v0.8.0 // indirect\n)\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0o600); err != nil {
+		t.Fatalf("write mod: %v", err)
+	}
+	proj, _, err := g.Load(slog.Default(), dir, dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := proj.(GoProject).Requires; !reflect.DeepEqual(got, []string{"github.com/hellofresh/health-go/v5", "golang.org/x/sync"}) {
+		t.Fatalf("unexpected requires %v", got)
+	}
+	var b strings.Builder
+	cfg := config.Config{Final: config.FinalConfig{Ports: []string{"3000"}}}
+	if err := g.GenerateDockerfile(slog.Default(), proj, nil, &b, cfg); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if !strings.Contains(b.String(), "CMD wget --no-verbose --tries=1 --spider http://localhost:3000/health || exit 1\n") {
+		t.Fatalf("expected inferred probe on the configured port, got: %s", b.String())
+	}
+
+	curl := config.Config{Final: config.FinalConfig{Healthcheck: config.HealthcheckConfig{Command: "curl -f http://localhost:3000/"}}}
+	if w := g.Check(proj, curl); len(w) != 1 || !strings.Contains(w[0], "runs curl") {
+		t.Fatalf("expected a missing curl warning, got %v", w)
+	}
+	curl.Base.Packages = []string{"curl"}
+	if w := g.Check(proj, curl); len(w) != 0 {
+		t.Fatalf("expected no warning when curl is installed, got %v", w)
+	}
+}
+
+func TestGoGenerator_EntrypointCmdWorkdir(t *testing.T) {
+	g := GoGenerator{}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0o600); err != nil {
+		t.Fatalf("write mod: %v", err)
+	}
+	proj, _, err := g.Load(slog.Default(), dir, dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	var b strings.Builder
+	if err := g.GenerateDockerfile(slog.Default(), proj, nil, &b, config.Config{}); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if !strings.Contains(b.String(), "FROM alpine:3.19 AS final\nWORKDIR /app\n") ||
+		!strings.HasSuffix(b.String(), "ENTRYPOINT [\"./app\"]\n") {
+		t.Fatalf("expected default WORKDIR and ENTRYPOINT, got: %s", b.String())
+	}
+
+	b.Reset()
+	cfg := config.Config{Final: config.FinalConfig{
+		Workdir:    "/srv",
+		Entrypoint: []string{"/srv/run.sh", "./app"},
+		Cmd:        []string{"serve", `--banner="hi"`},
+	}}
+	if err := g.GenerateDockerfile(slog.Default(), proj, nil, &b, cfg); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	want := "ENTRYPOINT [\"/srv/run.sh\", \"./app\"]\nCMD [\"serve\", \"--banner=\\\"hi\\\"\"]\n"
+	if !strings.Contains(b.String(), "AS final\nWORKDIR /srv\n") || !strings.HasSuffix(b.String(), want) {
+		t.Fatalf("expected configured WORKDIR, ENTRYPOINT and CMD, got: %s", b.String())
+	}
+}
+
+func TestGoGenerator_ExtraStages(t *testing.T) {
+	g := GoGenerator{}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0o600); err != nil {
+		t.Fatalf("write mod: %v", err)
+	}
+	proj, _, err := g.Load(slog.Default(), dir, dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	cfg := config.Config{Stages: map[string]config.Stage{
+		"migrations": {From: "build", Instructions: []string{"RUN go build -o /out/migrate ./cmd/migrate"},
+			CopyIntoFinal: map[string]string{"/out/migrate": "./migrate"}},
+	}}
+	var b strings.Builder
+	if err := g.GenerateDockerfile(slog.Default(), proj, nil, &b, cfg); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if !strings.Contains(b.String(), "go build -o /out/app ./...\n\nFROM build AS migrations\n"+
+		"RUN go build -o /out/migrate ./cmd/migrate\n\nFROM alpine:3.19 AS final\n") ||
+		!strings.Contains(b.String(), "COPY --from=build /out/app ./app\nCOPY --from=migrations /out/migrate ./migrate\nENTRYPOINT") {
+		t.Fatalf("expected migrations stage before final and copied into it, got: %s", b.String())
+	}
+
+	cfg.Stages["migrations"] = config.Stage{From: "build", Instructions: []string{"COPY --from=publish /app /app"}}
+	if err := g.GenerateDockerfile(slog.Default(), proj, nil, io.Discard, cfg); err == nil || !strings.Contains(err.Error(), "unknown stage 'publish'") {
+		t.Fatalf("expected unknown stage error, got %v", err)
+	}
+}
+
 func TestGoGenerator_DetectFileVsDir(t *testing.T) {
 	g := GoGenerator{}
 	dir := t.TempDir()
@@ -66,16 +201,43 @@ func TestGoGenerator_LoadInvalidPath(t *testing.T) {
 	if err := os.WriteFile(badFile, []byte("hello"), 0o600); err != nil {
 		t.Fatalf("write: %v", err)
 	}
-	if _, _, err := g.Load(badFile, dir); err == nil {
+	if _, _, err := g.Load(slog.Default(), badFile, dir); err == nil {
 		t.Fatalf("expected error for invalid file path")
 	}
 }
 
 func TestGoGenerator_GenerateInvalidProjectType(t *testing.T) {
 	g := GoGenerator{}
-	dir := t.TempDir()
-	dest := filepath.Join(dir, "Dockerfile")
-	if err := g.GenerateDockerfile(struct{}{}, nil, dest, config.Default()); err == nil {
+	if err := g.GenerateDockerfile(slog.Default(), struct{}{}, nil, io.Discard, config.Default()); err == nil {
 		t.Fatalf("expected error for invalid project type")
 	}
 }
+
+// TestGoGenerator_ConcurrentUse exercises the concurrency contract (run with -race).
+func TestGoGenerator_ConcurrentUse(t *testing.T) {
+	g := GoGenerator{}
+	root := t.TempDir()
+	var wg sync.WaitGroup
+	for i := range 4 {
+		dir := filepath.Join(root, fmt.Sprintf("svc%d", i))
+		if err := os.Mkdir(dir, 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		mod := fmt.Sprintf("module example.com/svc%d\n\ngo 1.23", i)
+		if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0o600); err != nil {
+			t.Fatalf("write mod: %v", err)
+		}
+		wg.Go(func() {
+			proj, additional, err := g.Load(slog.Default(), dir, root)
+			if err != nil {
+				t.Errorf("load: %v", err)
+				return
+			}
+			var b strings.Builder
+			if err := g.GenerateDockerfile(slog.Default(), proj, additional, &b, config.Default()); err != nil {
+				t.Errorf("generate: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,72 @@
+package templates
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/unidiff"
+)
+
+// Origin identifies the embedded template a custom template was exported from, as recorded in
+// the header written by Export.
+type Origin struct {
+	Language string
+	Version  string // dockerfile-gen version that exported the template
+	Hash     string // sha256 of the embedded template, hex encoded
+}
+
+// header is the first line of an exported template. It is a template comment trimming the
+// following newline, so an unmodified export renders exactly like the embedded template.
+var header = regexp.MustCompile(`^\{\{/\* dockerfile-gen ([a-z]+) template, exported by version (\S+), sha256:([0-9a-f]{64})\. .*\*/ -\}\}\n`)
+
+// Hash returns the hex encoded sha256 of a template text.
+func Hash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Export returns the embedded template of language prefixed with a header recording its origin.
+func Export(language, version, embedded string) string {
+	return fmt.Sprintf("{{/* dockerfile-gen %s template, exported by version %s, sha256:%s. "+
+		"Keep this line to be warned when the embedded template changes. */ -}}\n%s",
+		language, version, Hash(embedded), embedded)
+}
+
+// ParseHeader splits an exported template into its origin and the template text that follows the
+// header. ok is false when text has no header, in which case body is text.
+func ParseHeader(text string) (origin Origin, body string, ok bool) {
+	m := header.FindStringSubmatch(text)
+	if m == nil {
+		return Origin{}, text, false
+	}
+	return Origin{Language: m[1], Version: m[2], Hash: m[3]}, strings.TrimPrefix(text, m[0]), true
+}
+
+// Drift reports whether the custom template at path was exported from a template other than
+// embedded, i.e. the embedded template changed since (or the export is for another language).
+// Templates without a header are never reported.
+func Drift(path, embedded string) (Origin, bool, error) {
+	text, err := readTemplate(path)
+	if err != nil {
+		return Origin{}, false, err
+	}
+	origin, _, ok := ParseHeader(text)
+	return origin, ok && origin.Hash != Hash(embedded), nil
+}
+
+// Diff returns a unified diff from the embedded template to the custom template at path (header
+// excluded), or "" when they are identical.
+func Diff(path, embedded string) (string, error) {
+	text, err := readTemplate(path)
+	if err != nil {
+		return "", err
+	}
+	_, body, _ := ParseHeader(text)
+	if body == embedded {
+		return "", nil
+	}
+	return unidiff.Unified(embedded, body, path), nil
+}
@@ -0,0 +1,66 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+const embeddedTemplate = "FROM {{ .Image }}\nRUN echo done\n"
+
+func TestExportRendersLikeEmbedded(t *testing.T) {
+	exported := Export("go", "v1.2.3", embeddedTemplate)
+	origin, body, ok := ParseHeader(exported)
+	if !ok || body != embeddedTemplate {
+		t.Fatalf("header not parsed: ok=%v body=%q", ok, body)
+	}
+	if origin != (Origin{Language: "go", Version: "v1.2.3", Hash: Hash(embeddedTemplate)}) {
+		t.Fatalf("unexpected origin %+v", origin)
+	}
+	data := struct{ Image string }{"alpine:3.20"}
+	var embedded, custom strings.Builder
+	_ = Render(&embedded, "embedded", embeddedTemplate, config.Config{}, data)
+	if err := Render(&custom, "embedded", embeddedTemplate, config.Config{Template: writeTemplate(t, exported)}, data); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if custom.String() != embedded.String() {
+		t.Fatalf("exported template renders %q, embedded %q", custom.String(), embedded.String())
+	}
+}
+
+func TestParseHeaderWithoutHeader(t *testing.T) {
+	if _, body, ok := ParseHeader(embeddedTemplate); ok || body != embeddedTemplate {
+		t.Fatalf("expected no header, got ok=%v body=%q", ok, body)
+	}
+}
+
+func TestDriftAndDiff(t *testing.T) {
+	exported := Export("go", "v1.2.3", embeddedTemplate)
+	path := writeTemplate(t, exported)
+	if _, drifted, err := Drift(path, embeddedTemplate); err != nil || drifted {
+		t.Fatalf("fresh export should not drift: %v, %v", drifted, err)
+	}
+	if diff, err := Diff(path, embeddedTemplate); err != nil || diff != "" {
+		t.Fatalf("fresh export should not differ: %q, %v", diff, err)
+	}
+
+	upstream := strings.Replace(embeddedTemplate, "echo done", "echo updated", 1)
+	origin, drifted, err := Drift(path, upstream)
+	if err != nil || !drifted || origin.Version != "v1.2.3" {
+		t.Fatalf("expected drift from v1.2.3, got %+v %v %v", origin, drifted, err)
+	}
+	diff, err := Diff(path, upstream)
+	if err != nil || !strings.Contains(diff, "-RUN echo updated") || !strings.Contains(diff, "+RUN echo done") ||
+		strings.Contains(diff, "sha256:") {
+		t.Fatalf("unexpected diff %q, %v", diff, err)
+	}
+
+	// Templates without a header are never reported.
+	if _, drifted, err := Drift(writeTemplate(t, embeddedTemplate), upstream); err != nil || drifted {
+		t.Fatalf("template without header should not drift: %v, %v", drifted, err)
+	}
+	if _, _, err := Drift(path+".missing", upstream); err == nil {
+		t.Fatal("expected error for a missing template")
+	}
+}
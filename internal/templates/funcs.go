@@ -0,0 +1,145 @@
+package templates
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/common"
+)
+
+// Function documents a template function, as listed by 'dockerfile-gen templates functions'.
+type Function struct {
+	Name        string
+	Signature   string // arguments and result; the last argument is the one piped in
+	Description string
+	Example     string
+	fn          any // nil for functions bound per render (hook)
+}
+
+// library is the function set available to every Dockerfile and hook template, in listing order.
+var library = []Function{
+	{
+		Name: "quote", Signature: "quote STRING -> STRING", fn: quote,
+		Description: `Double-quotes a value for a Dockerfile key=value pair, escaping \ and ".`,
+		Example:     `LABEL description={{ .Config.Language | quote }}`,
+	},
+	{
+		Name: "jsonArray", Signature: "jsonArray LIST -> STRING", fn: jsonArray,
+		Description: "Renders a list as a JSON array for exec-form instructions.",
+		Example:     `ENTRYPOINT {{ jsonArray .Config.Final.Entrypoint }}`,
+	},
+	{
+		Name: "join", Signature: "join SEP LIST -> STRING", fn: join,
+		Description: "Joins the items of a list with SEP.",
+		Example:     `RUN apk add --no-cache {{ .Config.Base.Packages | join " " }}`,
+	},
+	{
+		Name: "default", Signature: "default DEFAULT VALUE -> VALUE", fn: defaultValue,
+		Description: "Returns VALUE, or DEFAULT when VALUE is empty (empty string, list or map, or nil).",
+		Example:     `FROM {{ .Config.Base.Image | default "alpine:3.20" }}`,
+	},
+	{
+		Name: "indent", Signature: "indent N STRING -> STRING", fn: indent,
+		Description: "Prefixes every non-empty line with N spaces.",
+		Example:     `{{ "apk add curl\nrm -rf /tmp/*" | indent 4 }}`,
+	},
+	{
+		Name: "lower", Signature: "lower STRING -> STRING", fn: strings.ToLower,
+		Description: "Converts to lower case.",
+		Example:     `{{ .Config.Language | lower }}`,
+	},
+	{
+		Name: "upper", Signature: "upper STRING -> STRING", fn: strings.ToUpper,
+		Description: "Converts to upper case.",
+		Example:     `ARG {{ .Config.Language | upper }}_VERSION`,
+	},
+	{
+		Name: "trimSuffix", Signature: "trimSuffix SUFFIX STRING -> STRING", fn: trimSuffix,
+		Description: "Removes SUFFIX from the end of STRING when present.",
+		Example:     `WORKDIR {{ .Config.Final.Workdir | trimSuffix "/" }}/data`,
+	},
+	{
+		Name: "relPath", Signature: "relPath BASE PATH -> STRING", fn: relPath,
+		Description: "Returns PATH relative to BASE with forward slashes, as used in COPY.",
+		Example:     `COPY {{ .Project.Path | relPath .Project.RootPath }} .`,
+	},
+	{
+		Name: "hook", Signature: "hook NAME -> STRING",
+		Description: "Inserts the content of a configured hook point (empty when not configured). Not available in hook files.",
+		Example:     `{{ hook "build-pre-restore" }}RUN dotnet restore`,
+	},
+}
+
+// Functions returns the documentation of every template function.
+func Functions() []Function {
+	return library
+}
+
+// funcMap returns the library as a template.FuncMap; per-render functions are added by Render.
+func funcMap() template.FuncMap {
+	m := make(template.FuncMap, len(library))
+	for _, f := range library {
+		if f.fn != nil {
+			m[f.Name] = f.fn
+		}
+	}
+	return m
+}
+
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func jsonArray(list []string) string {
+	if len(list) == 0 {
+		return "[]"
+	}
+	return common.ExecForm(list)
+}
+
+func join(sep string, list []string) string {
+	return strings.Join(list, sep)
+}
+
+func defaultValue(def, value any) any {
+	if value == nil {
+		return def
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if v.Len() == 0 {
+			return def
+		}
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return def
+		}
+	}
+	return value
+}
+
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = pad + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func trimSuffix(suffix, s string) string {
+	return strings.TrimSuffix(s, suffix)
+}
+
+func relPath(base, path string) (string, error) {
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,103 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+func TestFunctions(t *testing.T) {
+	cases := []struct {
+		name, text string
+		data       any
+		want       string
+	}{
+		{"quote", `{{ "a \"b\" c\\d" | quote }}`, nil, `"a \"b\" c\\d"`},
+		{"quote empty", `{{ "" | quote }}`, nil, `""`},
+		{"jsonArray", `{{ jsonArray . }}`, []string{"./app", "--port=<80>"}, `["./app", "--port=<80>"]`},
+		{"jsonArray empty", `{{ jsonArray . }}`, []string(nil), `[]`},
+		{"join", `{{ . | join " " }}`, []string{"curl", "git"}, `curl git`},
+		{"default empty", `{{ . | default "alpine" }}`, "", `alpine`},
+		{"default nil list", `{{ . | default "none" }}`, []string(nil), `none`},
+		{"default set", `{{ . | default "alpine" }}`, "debian", `debian`},
+		{"default number", `{{ . | default 1 }}`, 0, `0`},
+		{"indent", `{{ . | indent 2 }}`, "a\n\nb\n", "  a\n\n  b\n"},
+		{"lower", `{{ "GoLang" | lower }}`, nil, `golang`},
+		{"upper", `{{ "dotnet" | upper }}`, nil, `DOTNET`},
+		{"trimSuffix", `{{ "App.csproj" | trimSuffix ".csproj" }}`, nil, `App`},
+		{"trimSuffix absent", `{{ "App" | trimSuffix ".csproj" }}`, nil, `App`},
+		{"relPath", `{{ "/repo/src/App/App.csproj" | relPath "/repo" }}`, nil, `src/App/App.csproj`},
+		{"relPath parent", `{{ "/repo/lib" | relPath "/repo/src" }}`, nil, `../lib`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := Render(&b, tc.name, tc.text, config.Config{}, tc.data); err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			if b.String() != tc.want {
+				t.Fatalf("got %q, want %q", b.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestRelPathError(t *testing.T) {
+	var b strings.Builder
+	err := Render(&b, "rel", `{{ "/repo" | relPath "repo" }}`, config.Config{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "relPath") {
+		t.Fatalf("expected relPath error, got %v", err)
+	}
+}
+
+func TestFunctionsAvailableInHookFiles(t *testing.T) {
+	path := writeTemplate(t, "RUN echo {{ .Name | upper | quote }}")
+	cfg := config.Config{Hooks: map[string]config.Hook{"final-extra": {File: path}}}
+	var b strings.Builder
+	if err := Render(&b, "main", `{{ hook "final-extra" }}`, cfg, struct{ Name string }{"app"}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if b.String() != "RUN echo \"APP\"\n" {
+		t.Fatalf("got %q", b.String())
+	}
+}
+
+// Every documented function must exist and its example must render against template data shaped
+// like the generators' (Config plus a Project with RootPath and Path).
+func TestFunctionsDocumented(t *testing.T) {
+	data := struct {
+		Config  config.Config
+		Project struct{ RootPath, Path string }
+	}{
+		Config: config.Config{
+			Language: "go",
+			Base:     config.ImageConfig{Packages: []string{"curl"}},
+			Final:    config.FinalConfig{Workdir: "/app/", Entrypoint: []string{"./app"}},
+		},
+	}
+	data.Project.RootPath, data.Project.Path = "/repo", "/repo/cmd/app"
+	funcs := funcMap()
+	seen := map[string]bool{}
+	for _, f := range Functions() {
+		if f.Signature == "" || f.Description == "" || f.Example == "" {
+			t.Errorf("function %s is not fully documented: %+v", f.Name, f)
+		}
+		if _, ok := funcs[f.Name]; !ok && f.Name != "hook" {
+			t.Errorf("function %s is documented but not registered", f.Name)
+		}
+		if !strings.HasPrefix(f.Signature, f.Name+" ") {
+			t.Errorf("signature of %s should start with its name: %q", f.Name, f.Signature)
+		}
+		seen[f.Name] = true
+		var b strings.Builder
+		if err := Render(&b, f.Name, f.Example, config.Config{}, data); err != nil {
+			t.Errorf("example of %s does not render: %v", f.Name, err)
+		}
+	}
+	for name := range funcs {
+		if !seen[name] {
+			t.Errorf("function %s is registered but not documented", name)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package templates renders the Dockerfile templates of the generators: the embedded default or a
+// user-supplied text/template file given by the template config key or the --template flag, with
+// the configured hooks inserted at their hook points.
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+// Error is a template parse or execution failure located in the template file (Line and Column
+// are 1-based; 0 when unknown).
+type Error struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Line == 0:
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	case e.Column == 0:
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// templateError matches the "template: NAME:LINE[:COL]: message" prefix of text/template errors;
+// execution errors also repeat the name in 'executing "NAME" at <...>'.
+var templateError = regexp.MustCompile(`^template: (.*?):([0-9]+)(?::([0-9]+))?: (?:executing "(?:.*?)" )?(.*)$`)
+
+// Render executes a Dockerfile template with data into w. The template is the file set by
+// cfg.Template, or the embedded text otherwise (name identifies it in errors). Templates and hook
+// files can call the functions of the shared library (see Functions). The hooks of cfg
+// are rendered first and inserted where the template calls {{ hook "name" }}; configuring a hook
+// that is not one of config.HookNames is an error. Failures of a template or hook file are
+// returned as *Error naming the file and line.
+func Render(w io.Writer, name, embedded string, cfg config.Config, data any) error {
+	hooks, err := renderHooks(cfg.Hooks, data)
+	if err != nil {
+		return err
+	}
+	text := embedded
+	if cfg.Template != "" {
+		name = cfg.Template
+		if text, err = readTemplate(cfg.Template); err != nil {
+			return err
+		}
+	}
+	funcs := funcMap()
+	funcs["hook"] = func(hook string) (string, error) {
+		if !slices.Contains(config.HookNames, hook) {
+			return "", fmt.Errorf("unknown hook '%s'", hook)
+		}
+		return hooks[hook], nil
+	}
+	tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return locate(name, err)
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return locate(name, err)
+	}
+	return nil
+}
+
+// renderHooks returns the content of each configured hook: its snippet followed by its rendered
+// file, each ending with a newline.
+func renderHooks(hooks map[string]config.Hook, data any) (map[string]string, error) {
+	out := make(map[string]string, len(hooks))
+	for _, name := range slices.Sorted(maps.Keys(hooks)) {
+		if !slices.Contains(config.HookNames, name) {
+			return nil, fmt.Errorf("unknown hook '%s' in configuration (available hooks: %s)",
+				name, strings.Join(config.HookNames, ", "))
+		}
+		h := hooks[name]
+		var b strings.Builder
+		if h.Snippet != "" {
+			b.WriteString(strings.TrimRight(h.Snippet, "\n") + "\n")
+		}
+		if h.File != "" {
+			text, err := readTemplate(h.File)
+			if err != nil {
+				return nil, err
+			}
+			tmpl, err := template.New(h.File).Funcs(funcMap()).Parse(text)
+			if err != nil {
+				return nil, locate(h.File, err)
+			}
+			var rendered strings.Builder
+			if err := tmpl.Execute(&rendered, data); err != nil {
+				return nil, locate(h.File, err)
+			}
+			if s := strings.TrimRight(rendered.String(), "\n"); s != "" {
+				b.WriteString(s + "\n")
+			}
+		}
+		out[name] = b.String()
+	}
+	return out, nil
+}
+
+func readTemplate(path string) (string, error) {
+	content, err := os.ReadFile(filepath.Clean(path)) // #nosec G304 - user selected template file
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("template file not found: %s", path)
+		}
+		return "", fmt.Errorf("error reading template %s: %w", path, err)
+	}
+	return string(content), nil
+}
+
+// locate converts a text/template error about the template named file into an *Error.
+func locate(file string, err error) error {
+	m := templateError.FindStringSubmatch(err.Error())
+	if m == nil || m[1] != file {
+		return &Error{File: file, Message: err.Error()}
+	}
+	line, _ := strconv.Atoi(m[2])
+	column, _ := strconv.Atoi(m[3])
+	return &Error{File: file, Line: line, Column: column, Message: m[4]}
+}
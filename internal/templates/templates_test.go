@@ -0,0 +1,93 @@
+package templates
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+func writeTemplate(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "Dockerfile.tmpl")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return path
+}
+
+func TestRenderEmbeddedAndFile(t *testing.T) {
+	data := struct{ Image string }{"alpine:3.20"}
+	var b strings.Builder
+	if err := Render(&b, "embedded", "FROM {{ .Image }}\n", config.Config{}, data); err != nil || b.String() != "FROM alpine:3.20\n" {
+		t.Fatalf("embedded render: %q, %v", b.String(), err)
+	}
+	b.Reset()
+	path := writeTemplate(t, "# custom\nFROM {{ .Image }} AS final\n")
+	if err := Render(&b, "embedded", "FROM {{ .Image }}\n", config.Config{Template: path}, data); err != nil || b.String() != "# custom\nFROM alpine:3.20 AS final\n" {
+		t.Fatalf("file render: %q, %v", b.String(), err)
+	}
+}
+
+func TestRenderErrorsNameFileAndLine(t *testing.T) {
+	data := struct{ Image string }{"alpine:3.20"}
+	cases := []struct {
+		name, content string
+		location      string // expected right after the file name
+		message       string
+	}{
+		{"parse", "FROM {{ .Image }}\nRUN {{ if }}\n", ":2: ", "missing value for if"},
+		{"execute", "FROM {{ .Image }}\n\nCOPY {{ .Project.Name }} .\n", ":3:", "can't evaluate field Project"},
+	}
+	for _, c := range cases {
+		path := writeTemplate(t, c.content)
+		err := Render(&strings.Builder{}, "embedded", "", config.Config{Template: path}, data)
+		var tmplErr *Error
+		if !errors.As(err, &tmplErr) || tmplErr.File != path {
+			t.Fatalf("%s: expected a template error for %s, got %v", c.name, path, err)
+		}
+		if !strings.HasPrefix(err.Error(), path+c.location) || !strings.Contains(err.Error(), c.message) {
+			t.Fatalf("%s: expected %q after the file name and %q, got %q", c.name, c.location, c.message, err)
+		}
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.tmpl")
+	if err := Render(&strings.Builder{}, "embedded", "", config.Config{Template: missing}, data); err == nil ||
+		err.Error() != "template file not found: "+missing {
+		t.Fatalf("expected template file not found error, got %v", err)
+	}
+}
+
+func TestRenderHooks(t *testing.T) {
+	data := struct{ Image string }{"alpine:3.20"}
+	const text = "FROM {{ .Image }}\n{{ hook \"build-pre-restore\" }}RUN restore\n{{ hook \"final-extra\" }}"
+	hookFile := writeTemplate(t, "# from {{ .Image }}\nUSER app\n\n")
+	cfg := config.Config{Hooks: map[string]config.Hook{
+		"build-pre-restore": {Snippet: "RUN apk add --no-cache icu-libs"},
+		"final-extra":       {Snippet: "STOPSIGNAL SIGTERM\n", File: hookFile},
+	}}
+	var b strings.Builder
+	if err := Render(&b, "embedded", text, cfg, data); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	want := "FROM alpine:3.20\nRUN apk add --no-cache icu-libs\nRUN restore\nSTOPSIGNAL SIGTERM\n# from alpine:3.20\nUSER app\n"
+	if b.String() != want {
+		t.Fatalf("unexpected output:\n got %q\nwant %q", b.String(), want)
+	}
+
+	b.Reset()
+	if err := Render(&b, "embedded", text, config.Config{}, data); err != nil || b.String() != "FROM alpine:3.20\nRUN restore\n" {
+		t.Fatalf("expected empty hooks to render nothing, got %q, %v", b.String(), err)
+	}
+
+	unknown := config.Config{Hooks: map[string]config.Hook{"pre-restore": {Snippet: "RUN true"}}}
+	if err := Render(&b, "embedded", text, unknown, data); err == nil || !strings.Contains(err.Error(), "unknown hook 'pre-restore'") {
+		t.Fatalf("expected unknown hook error, got %v", err)
+	}
+	if err := Render(&b, "embedded", `{{ hook "nope" }}`, config.Config{}, data); err == nil || !strings.Contains(err.Error(), "unknown hook 'nope'") {
+		t.Fatalf("expected unknown hook point error, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
+)
+
+// addLanguageFlag registers --language / -l with help text derived from the registry.
+func addLanguageFlag(fs *pflag.FlagSet, target *string) {
+	fs.StringVarP(target, "language", "l", "",
+		fmt.Sprintf("Language override (%s). If empty attempts autodetect or config",
+			strings.Join(generator.Names(), ", ")))
+}
+
+// registerLanguageCompletion completes --language with the registered generator names.
+func registerLanguageCompletion(cmd *cobra.Command) {
+	_ = cmd.RegisterFlagCompletionFunc("language", completeLanguages)
+}
+
+// completeLanguages completes --language with the registered generator names, offering first the
+// language set by the project's .dockerbuild files, if any.
+func completeLanguages(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	var out []string
+	configured := ""
+	cfg, sources := completionConfig(cmd)
+	if _, err := lookupGenerator(cfg.Language); cfg.Language != "" && err == nil {
+		configured = strings.ToLower(cfg.Language)
+		out = append(out, configured+"\tfrom "+resolveTrace{configSources: sources}.sourceOf("language"))
+	}
+	for _, g := range generator.All() {
+		if g.Name() == configured {
+			continue
+		}
+		entry := g.Name()
+		if d, ok := g.(generator.Describer); ok {
+			entry += "\t" + d.Describe().Summary
+		}
+		out = append(out, entry)
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// lookupGenerator returns the generator registered under name or an error listing the valid names.
+func lookupGenerator(name string) (generator.Generator, error) {
+	g, ok := generator.Get(strings.ToLower(name))
+	if !ok {
+		return nil, fmt.Errorf("unsupported language '%s' (available: %s)", name, strings.Join(generator.Names(), ", "))
+	}
+	return g, nil
+}
+
+// newLanguagesCmd builds the 'languages' subcommand listing the registered generators.
+func newLanguagesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "languages",
+		Aliases: []string{"generators"},
+		Short:   "List the registered generators, what they detect, their default images and config keys",
+		Args:    cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			writeLanguages(os.Stdout)
+			return nil
+		},
+	}
+}
+
+func writeLanguages(out io.Writer) {
+	for i, g := range generator.All() {
+		if i > 0 {
+			_, _ = fmt.Fprintln(out)
+		}
+		var desc generator.Description
+		if d, ok := g.(generator.Describer); ok {
+			desc = d.Describe()
+		}
+		_, _ = fmt.Fprintln(out, g.Name())
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		if desc.Summary != "" {
+			_, _ = fmt.Fprintf(tw, "  summary:\t%s\n", desc.Summary)
+		}
+		if len(desc.Detects) > 0 {
+			_, _ = fmt.Fprintf(tw, "  detects:\t%s\n", strings.Join(desc.Detects, "; "))
+		}
+		if d, ok := g.(generator.Defaulter); ok {
+			defaults := d.DefaultImages()
+			_, _ = fmt.Fprintf(tw, "  base image:\t%s\n", defaults.Base)
+			_, _ = fmt.Fprintf(tw, "  base-build image:\t%s\n", defaults.BaseBuild)
+		}
+		if len(desc.ConfigKeys) > 0 {
+			_, _ = fmt.Fprintf(tw, "  config keys:\t%s\n", strings.Join(desc.ConfigKeys, ", "))
+		}
+		_ = tw.Flush()
+	}
+}
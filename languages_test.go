@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
+)
+
+func TestLanguagesCmd(t *testing.T) {
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"languages"})
+	out := captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	for _, g := range generator.All() {
+		if !strings.Contains(out, g.Name()+"\n") {
+			t.Fatalf("expected generator %s listed, got:\n%s", g.Name(), out)
+		}
+		if _, ok := g.(generator.Describer); !ok {
+			t.Fatalf("generator %s should implement Describer", g.Name())
+		}
+		if _, ok := g.(generator.Defaulter); !ok {
+			t.Fatalf("generator %s should implement Defaulter", g.Name())
+		}
+	}
+	if !strings.Contains(out, "dotnet.sdk-version") || !strings.Contains(out, "golang:${GO_VERSION}-alpine") {
+		t.Fatalf("expected config keys and default images, got:\n%s", out)
+	}
+}
+
+func TestLanguageCompletionAndValidation(t *testing.T) {
+	names, _ := completeLanguages(nil, nil, "")
+	if len(names) != len(generator.All()) || !strings.HasPrefix(names[0], "dotnet\t") {
+		t.Fatalf("unexpected completions %v", names)
+	}
+	if _, err := lookupGenerator("GO"); err != nil {
+		t.Fatalf("lookup should be case-insensitive: %v", err)
+	}
+	if _, err := lookupGenerator("cobol"); err == nil || !strings.Contains(err.Error(), "available: dotnet, go") {
+		t.Fatalf("expected error listing available languages, got %v", err)
+	}
+}
@@ -1,19 +1,17 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/spf13/cobra"
 
-	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
 	_ "github.com/n2jsoft-public-org/dockerfile-generator/internal/dotnet" // register dotnet generator
-	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
 	_ "github.com/n2jsoft-public-org/dockerfile-generator/internal/golang" // register go generator
-	"github.com/n2jsoft-public-org/dockerfile-generator/internal/unidiff"
 )
 
 var (
@@ -21,16 +19,28 @@ var (
 	commit  = "none"
 	date    = "unknown"
 	logger  *slog.Logger
+	// logLevel is shared by the global logger and the per-project loggers of parallel runs.
+	logLevel slog.LevelVar
 )
 
+// exitCodeStale is returned by --check when at least one Dockerfile is missing or out of date.
+const exitCodeStale = 2
+
+// exitError carries a dedicated process exit code; silent suppresses the error message.
+type exitError struct {
+	code   int
+	err    error
+	silent bool
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
 // newRootCmd builds the root cobra command (extracted for testability).
 //
 //go:noinline
 func newRootCmd() *cobra.Command {
-	var projectPath string
-	var dockerfileName string
-	var language string
-	var dryRun bool
+	var opts generateOptions
 	var versionLower bool
 	var versionUpper bool
 	var verbose bool
@@ -46,7 +56,8 @@ func newRootCmd() *cobra.Command {
 			if verbose {
 				lvl = slog.LevelDebug
 			}
-			logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: lvl}))
+			logLevel.Set(lvl)
+			logger = newLogger(os.Stderr)
 			slog.SetDefault(logger)
 			Debugf("starting command with args: %v", os.Args[1:])
 		},
@@ -57,147 +68,29 @@ func newRootCmd() *cobra.Command {
 				return nil
 			}
 
-			if projectPath == "" {
-				// Should not happen because default is set, but guard anyway.
-				projectPath = "."
-			}
-			Debugf("project path provided/resolved: %s", projectPath)
-
-			if _, err := os.Stat(projectPath); os.IsNotExist(err) {
-				return fmt.Errorf("project path not found: %s", projectPath)
-			}
-
-			rootPath := findRepositoryRoot(projectPath)
-			if rootPath == "" {
-				return fmt.Errorf("cannot find repository root")
-			}
-			Debugf("repository root: %s", rootPath)
-
-			projectDirectory := projectPath
-			if fi, err := os.Stat(projectDirectory); err == nil && !fi.IsDir() {
-				projectDirectory = filepath.Dir(projectDirectory)
-			}
-			Debugf("project directory resolved: %s", projectDirectory)
-
-			cfgPath := filepath.Join(projectDirectory, config.DefaultDockerBuildFileName)
-			cfg := config.Default()
-			configLoaded := false
-			if data, err := os.Stat(cfgPath); err == nil && !data.IsDir() {
-				loaded, err2 := config.Load(cfgPath)
-				if err2 != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to load config: %v\n", err2)
-					Warnf("failed to load config file %s: %v", cfgPath, err2)
-				} else {
-					cfg = loaded
-					configLoaded = true
-					Debugf("loaded config from %s", cfgPath)
-				}
-			} else {
-				Debugf("no config file found at %s (using defaults)", cfgPath)
-			}
-
-			// If language flag not set, use config only if a config file was loaded
-			if language == "" && configLoaded && cfg.Language != "" {
-				language = strings.ToLower(cfg.Language)
-				Debugf("language set from config: %s", language)
-			}
-
-			// Autodetect if still empty
-			if language == "" {
-				for _, g := range generator.All() {
-					ok, _ := g.Detect(projectPath)
-					Debugf("detect attempt with generator %s => %v", g.Name(), ok)
-					if ok {
-						language = g.Name()
-						break
-					}
-				}
-			}
-			if language == "" {
-				return fmt.Errorf("could not detect language; provide -l / --language")
-			}
-			Debugf("final language resolved: %s", language)
-
-			gen, exists := generator.Get(strings.ToLower(language))
-			if !exists {
-				return fmt.Errorf("unsupported language '%s'", language)
-			}
-			Debugf("using generator: %s", gen.Name())
-
-			project, additional, err := gen.Load(projectPath, rootPath)
-			if err != nil {
-				return fmt.Errorf("error loading project: %w", err)
-			}
-			Debugf("loaded project; additional files: %d", len(additional))
-			for _, a := range additional {
-				Debugf("additional context file: %s", a.GetRelativePath())
-			}
-
-			dest := filepath.Join(projectDirectory, dockerfileName)
-			Debugf("output Dockerfile path: %s", dest)
-
-			if dryRun {
-				Infof("running in dry-run mode")
-				tmp, err := os.CreateTemp("", "dockerfile-gen-*")
-				if err != nil {
-					return fmt.Errorf("error creating temp file: %w", err)
-				}
-				tmpPath := tmp.Name()
-				_ = tmp.Close()
-				defer func() { _ = os.Remove(tmpPath) }()
-				if err := gen.GenerateDockerfile(project, additional, tmpPath, cfg); err != nil {
-					return fmt.Errorf("error generating Dockerfile (dry-run): %w", err)
-				}
-				Debugf("generated temporary Dockerfile at %s", tmpPath)
-				newBytes, err := os.ReadFile(tmpPath) // #nosec G304 - tmpPath created via os.CreateTemp
-				if err != nil {
-					return fmt.Errorf("error reading generated Dockerfile: %w", err)
-				}
-				var oldBytes []byte
-				if _, err := os.Stat(dest); err == nil {
-					oldBytes, _ = os.ReadFile(dest) // #nosec G304 - dest is within project directory
-				}
-				Debugf("existing Dockerfile size: %d bytes, new size: %d bytes", len(oldBytes), len(newBytes))
-				if string(oldBytes) == string(newBytes) {
-					fmt.Printf("Dry run: no changes. %s is up to date.\n", dest)
-					Infof("no changes detected compared to existing %s", dest)
-					return nil
-				}
-				diff := unidiff.Unified(string(oldBytes), string(newBytes), dest)
-				fmt.Println(diff)
-				fmt.Println("Dry run: no file written.")
-				Infof("differences displayed; not writing file")
-				return nil
-			}
-
-			Infof("generating Dockerfile for %s (%s)", projectPath, language)
-			if err := gen.GenerateDockerfile(project, additional, dest, cfg); err != nil {
-				return fmt.Errorf("error generating Dockerfile: %w", err)
-			}
-			fmt.Printf("Successfully generated %s (%s) for project %s\n", dockerfileName, language, projectPath)
-			Infof("generation complete: %s", dest)
-			return nil
+			return runGenerate(os.Stdout, os.Stderr, opts)
 		},
 	}
 
-	// Flags
+	addGenerateFlags(rootCmd.Flags(), &opts)
+	rootCmd.MarkFlagsMutuallyExclusive("dry-run", "check")
+	registerCompletions(rootCmd)
 	f := rootCmd.Flags()
-	f.StringVarP(&projectPath, "path", "p", ".",
-		"Path to the project (directory, .csproj, or go.mod). Defaults to current directory.")
-	f.StringVarP(&dockerfileName, "dockerfile", "f", "Dockerfile", "Name of the Dockerfile to generate")
-	f.StringVarP(&language, "language", "l", "",
-		"Language override (dotnet, go). If empty attempts autodetect or config")
-	f.BoolVarP(&dryRun, "dry-run", "d", false, "Do not write file; show diff between existing and generated content")
 	f.BoolVarP(&versionLower, "version", "v", false, "Print version information and exit")
 	// Uppercase alias
 	f.BoolVarP(&versionUpper, "Version", "V", false, "Print version information and exit")
 	_ = f.MarkHidden("Version") // keep -V working but hide from help
 	f.BoolVarP(&verbose, "verbose", "", false, "Enable verbose (debug) logging to stderr")
+	rootCmd.CompletionOptions.DisableDefaultCmd = true // replaced by newCompletionCmd
+	rootCmd.AddCommand(newGenerateCmd(), newInitCmd(), newExplainCmd(), newLanguagesCmd(),
+		newWatchCmd(), newCompletionCmd(), newConfigCmd(), newTemplatesCmd())
 
 	rootCmd.Example = `  dockerfile-gen            # use current directory
   dockerfile-gen -p ./src/WebApi/WebApi.csproj
   dockerfile-gen -p ./service -l go -f Dockerfile.service
   dockerfile-gen -p ./src/WebApi -d
+  dockerfile-gen -p ./src/WebApi --check
+  dockerfile-gen --all --exclude 'samples/**'
   dockerfile-gen -v
   dockerfile-gen --verbose`
 
@@ -206,12 +99,26 @@ func newRootCmd() *cobra.Command {
 
 func main() {
 	if err := newRootCmd().Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		Errorf("command failed: %v", err)
-		os.Exit(1)
+		code := 1
+		silent := false
+		var ee *exitError
+		if errors.As(err, &ee) {
+			code = ee.code
+			silent = ee.silent
+		}
+		if !silent {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			Errorf("command failed: %v", err)
+		}
+		os.Exit(code)
 	}
 }
 
+// newLogger returns a text logger writing to w at the current log level.
+func newLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: &logLevel}))
+}
+
 // Helper formatted logging wrappers around slog to keep minimal changes.
 func Debugf(format string, a ...any) {
 	if logger != nil {
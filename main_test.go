@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"io"
 	"log/slog"
 	"os"
@@ -23,6 +24,23 @@ func captureStdout(_ *testing.T, fn func()) string { // underscore for unused pa
 	return string(b)
 }
 
+// captureStderr returns what fn writes to os.Stderr, read while fn runs so verbose logs cannot
+// fill the pipe.
+func captureStderr(_ *testing.T, fn func()) string {
+	old := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	read := make(chan []byte)
+	go func() {
+		b, _ := io.ReadAll(r)
+		read <- b
+	}()
+	fn()
+	_ = w.Close()
+	os.Stderr = old
+	return string(<-read)
+}
+
 func TestRootCmd_Version(t *testing.T) {
 	cmd := newRootCmd()
 	cmd.SetArgs([]string{"--version"})
@@ -118,14 +136,17 @@ func TestRootCmd_DryRunNoChange(t *testing.T) {
 	}
 	// Pre-generate Dockerfile using generator directly so dry-run finds no changes.
 	g := golang.GoGenerator{}
-	proj, _, err := g.Load(dir, dir)
+	proj, _, err := g.Load(slog.Default(), dir, dir)
 	if err != nil {
 		t.Fatalf("load: %v", err)
 	}
-	dest := filepath.Join(dir, "Dockerfile")
-	if err := g.GenerateDockerfile(proj, nil, dest, config.Default()); err != nil {
+	var b strings.Builder
+	if err := g.GenerateDockerfile(slog.Default(), proj, nil, &b, config.Default()); err != nil {
 		t.Fatalf("gen: %v", err)
 	}
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(b.String()), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
 	cmd := newRootCmd()
 	cmd.SetArgs([]string{"-p", dir, "-l", "go", "-d"})
 	out := captureStdout(t, func() {
@@ -233,6 +254,189 @@ func TestRootCmd_InvalidConfigWarning(t *testing.T) {
 	}) // ignore error; expecting success with warning
 }
 
+func TestRootCmd_StrictConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(dir, "go.mod"), sampleGoMod)
+	writeFile(t, filepath.Join(dir, ".dockerbuild"), "base_build:\n  image: golang:1.23-alpine\n")
+
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"-p", dir, "-d"})
+	captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("unknown keys should only warn without --strict: %v", err)
+		}
+	})
+
+	cmd = newRootCmd()
+	cmd.SetArgs([]string{"-p", dir, "-d", "--strict"})
+	var err error
+	captureStdout(t, func() { err = cmd.Execute() })
+	want := filepath.Join(dir, ".dockerbuild") + ":1:1: unknown key 'base_build' (did you mean 'base-build'?)"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected strict failure with %q, got %v", want, err)
+	}
+}
+
+func TestRootCmd_ConfigUnsetVariable(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(dir, "go.mod"), sampleGoMod)
+	writeFile(t, filepath.Join(dir, ".dockerbuild"), "base:\n  image: ${DG_TEST_REGISTRY}/alpine:3.20\n")
+
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"-p", dir, "-d"})
+	var err error
+	captureStdout(t, func() { err = cmd.Execute() })
+	if err == nil || !strings.Contains(err.Error(), "unset variable DG_TEST_REGISTRY (write $${DG_TEST_REGISTRY}") {
+		t.Fatalf("expected unset variable to fail even without --strict, got %v", err)
+	}
+
+	t.Setenv("DG_TEST_REGISTRY", "registry.local")
+	cmd = newRootCmd()
+	cmd.SetArgs([]string{"-p", dir, "-d"})
+	out := captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	if !strings.Contains(out, "FROM registry.local/alpine:3.20 AS final") {
+		t.Fatalf("expected expanded image in diff, got %q", out)
+	}
+}
+
+func TestRootCmd_Profile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(dir, "go.mod"), sampleGoMod)
+	writeFile(t, filepath.Join(dir, ".dockerbuild"), "base:\n  image: alpine:3.20\n"+
+		"profiles:\n  debug:\n    base:\n      packages: [strace]\n  prod:\n    base:\n      image: alpine:3.21\n")
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := newRootCmd()
+		cmd.SetArgs(append([]string{"-p", dir}, args...))
+		captureStdout(t, func() {
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("execute %v: %v", args, err)
+			}
+		})
+	}
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name)) // #nosec G304 - test file
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		return string(data)
+	}
+
+	run("--profile", "debug", "-f", "Dockerfile.debug")
+	t.Setenv(profileEnvVar, "prod")
+	run()
+	if got := read("Dockerfile.debug"); !strings.Contains(got, "FROM alpine:3.20 AS final") || !strings.Contains(got, "strace") {
+		t.Fatalf("expected debug profile in Dockerfile.debug:\n%s", got)
+	}
+	if got := read("Dockerfile"); !strings.Contains(got, "FROM alpine:3.21 AS final") || strings.Contains(got, "strace") {
+		t.Fatalf("expected prod profile (from %s) in Dockerfile:\n%s", profileEnvVar, got)
+	}
+
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"-p", dir, "--profile", "staging", "-d"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "unknown profile 'staging'") {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+}
+
+func TestRootCmd_ConfigFlag(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(dir, "go.mod"), sampleGoMod)
+	writeFile(t, filepath.Join(dir, ".dockerbuild.yaml"), "base:\n  image: alpine:3.20\n")
+	custom := filepath.Join(t.TempDir(), "ci.json")
+	writeFile(t, custom, `{"base": {"image": "alpine:3.21"}}`)
+
+	generate := func(args ...string) (string, error) {
+		t.Helper()
+		cmd := newRootCmd()
+		cmd.SetArgs(append([]string{"-p", dir, "-d"}, args...))
+		var err error
+		out := captureStdout(t, func() { err = cmd.Execute() })
+		return out, err
+	}
+	if out, err := generate(); err != nil || !strings.Contains(out, "FROM alpine:3.20 AS final") {
+		t.Fatalf("expected .dockerbuild.yaml to be used, got %v:\n%s", err, out)
+	}
+	if out, err := generate("--config", custom); err != nil || !strings.Contains(out, "FROM alpine:3.21 AS final") {
+		t.Fatalf("expected --config file to replace the cascade, got %v:\n%s", err, out)
+	}
+	if _, err := generate("--config", filepath.Join(dir, "missing.yaml")); err == nil ||
+		!strings.Contains(err.Error(), "config file not found") {
+		t.Fatalf("expected config file not found error, got %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, ".dockerbuild"), "base:\n  image: alpine:3.19\n")
+	if _, err := generate(); err == nil || !strings.Contains(err.Error(), "multiple configuration files") {
+		t.Fatalf("expected multiple configuration files error, got %v", err)
+	}
+}
+
+func TestRootCmd_CustomTemplate(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(dir, "go.mod"), sampleGoMod)
+	writeFile(t, filepath.Join(dir, "ci", "go.tmpl"), "# key template\nFROM {{ .RuntimeImage }}\nENTRYPOINT {{ .Entrypoint }}\n")
+	writeFile(t, filepath.Join(dir, ".dockerbuild"), "template: ci/go.tmpl\n")
+	flagTemplate := filepath.Join(t.TempDir(), "flag.tmpl")
+	writeFile(t, flagTemplate, "# flag template\n{{ range .Project.Missing }}{{ end }}\n")
+
+	generate := func(args ...string) (string, error) {
+		t.Helper()
+		cmd := newRootCmd()
+		cmd.SetArgs(append([]string{"-p", dir, "-f", "-"}, args...))
+		var err error
+		out := captureStdout(t, func() { err = cmd.Execute() })
+		return out, err
+	}
+	if out, err := generate(); err != nil || out != "# key template\nFROM alpine:3.19\nENTRYPOINT [\"./app\"]\n" {
+		t.Fatalf("expected the template key (relative to .dockerbuild) to be rendered, got %v:\n%s", err, out)
+	}
+	_, err := generate("--template", flagTemplate)
+	if err == nil || !strings.Contains(err.Error(), flagTemplate+":2:") {
+		t.Fatalf("expected --template to win and its error to name the file and line, got %v", err)
+	}
+}
+
+func TestRootCmd_Hooks(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(dir, "go.mod"), sampleGoMod)
+	writeFile(t, filepath.Join(dir, "hooks", "user.tmpl"), "USER nobody\n")
+	writeFile(t, filepath.Join(dir, ".dockerbuild"), "hooks:\n"+
+		"  build-pre-restore:\n    snippet: RUN apk add --no-cache git\n"+
+		"  final-extra:\n    file: hooks/user.tmpl\n")
+
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"-p", dir, "-f", "-"})
+	var err error
+	out := captureStdout(t, func() { err = cmd.Execute() })
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !strings.Contains(out, "COPY go.mod* .\nRUN apk add --no-cache git\n# Download modules") ||
+		!strings.HasSuffix(out, "ENTRYPOINT [\"./app\"]\nUSER nobody\n") {
+		t.Fatalf("expected hooks at their hook points, got:\n%s", out)
+	}
+
+	writeFile(t, filepath.Join(dir, ".dockerbuild"), "hooks:\n  pre-restore:\n    snippet: RUN true\n")
+	cmd = newRootCmd()
+	cmd.SetArgs([]string{"-p", dir, "-d"})
+	captureStdout(t, func() { err = cmd.Execute() })
+	if err == nil || !strings.Contains(err.Error(), "unknown hook 'pre-restore'") {
+		t.Fatalf("expected unknown hook error, got %v", err)
+	}
+}
+
 func TestRootCmd_NoGitRootError(t *testing.T) {
 	dir := t.TempDir()
 	// NOTE: no .git created
@@ -255,3 +459,102 @@ func TestLogWrappers(t *testing.T) {
 	Warnf("warn test")
 	Errorf("error test")
 }
+
+func TestRootCmd_CheckUpToDate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0o750); err != nil {
+		t.Fatalf("mkdir git: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.23\n"),
+		0o600); err != nil {
+		t.Fatalf("write mod: %v", err)
+	}
+	gen := newRootCmd()
+	gen.SetArgs([]string{"-p", dir, "-l", "go"})
+	captureStdout(t, func() {
+		if err := gen.Execute(); err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+	})
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"-p", dir, "-l", "go", "--check"})
+	out := captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	if !strings.Contains(out, "UP-TO-DATE") {
+		t.Fatalf("expected up-to-date summary, got %q", out)
+	}
+}
+
+func TestRootCmd_CheckStaleAndMissing(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0o750); err != nil {
+		t.Fatalf("mkdir git: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.23\n"),
+		0o600); err != nil {
+		t.Fatalf("write mod: %v", err)
+	}
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"-p", dir, "-l", "go", "--check"})
+	var err error
+	out := captureStdout(t, func() { err = cmd.Execute() })
+	var ee *exitError
+	if !errors.As(err, &ee) || ee.code != exitCodeStale {
+		t.Fatalf("expected stale exit code for missing Dockerfile, got %v", err)
+	}
+	if !strings.Contains(out, "MISSING") {
+		t.Fatalf("expected missing summary, got %q", out)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM scratch\n"), 0o600); err != nil {
+		t.Fatalf("write existing dockerfile: %v", err)
+	}
+	cmd = newRootCmd()
+	cmd.SetArgs([]string{"-p", dir, "-l", "go", "--check", "-q"})
+	errOut := captureStderr(t, func() {
+		out = captureStdout(t, func() { err = cmd.Execute() })
+	})
+	if !errors.As(err, &ee) || ee.code != exitCodeStale || !ee.silent {
+		t.Fatalf("expected silent stale exit error, got %v", err)
+	}
+	if out != "" || errOut != "" {
+		t.Fatalf("expected no output in quiet mode, got %q and %q on stderr", out, errOut)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "Dockerfile")) // #nosec G304 - test file
+	if string(data) != "FROM scratch\n" {
+		t.Fatalf("check mode must not write the Dockerfile")
+	}
+}
+
+func TestRootCmd_DockerfileToStdout(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0o750); err != nil {
+		t.Fatalf("mkdir git: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.23\n"),
+		0o600); err != nil {
+		t.Fatalf("write mod: %v", err)
+	}
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"-p", dir, "-f", "-"})
+	out := captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	if !strings.HasPrefix(out, "# Generated from dockerfile-generator") || strings.Contains(out, "Successfully") {
+		t.Fatalf("expected only the Dockerfile on stdout, got %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Dockerfile")); err == nil {
+		t.Fatalf("no Dockerfile should be written when streaming to stdout")
+	}
+
+	cmd = newRootCmd()
+	cmd.SetArgs([]string{"-p", dir, "-f", "-", "--check"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Fatalf("expected stdout + check to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+// projectReport is the machine-readable document emitted per project with --output json.
+type projectReport struct {
+	Language        string         `json:"language,omitempty"`
+	Project         string         `json:"project"`
+	RepoRoot        string         `json:"repoRoot,omitempty"`
+	Dockerfile      string         `json:"dockerfile,omitempty"`
+	AdditionalFiles []string       `json:"additionalFiles"`
+	Config          *config.Config `json:"config,omitempty"`
+	Profile         string         `json:"profile,omitempty"`
+	Status          string         `json:"status,omitempty"`
+	Changed         bool           `json:"changed"`
+	Warnings        []string       `json:"warnings"`
+	Error           string         `json:"error,omitempty"`
+}
+
+func newProjectReport(res projectResult) projectReport {
+	rep := projectReport{
+		Language:        res.language,
+		Project:         res.path,
+		Dockerfile:      res.dest,
+		Status:          string(res.status),
+		Changed:         res.changed,
+		AdditionalFiles: []string{},
+		Warnings:        []string{},
+	}
+	if res.err != nil {
+		rep.Error = res.err.Error()
+	}
+	if p := res.project; p != nil {
+		rep.RepoRoot = p.rootPath
+		cfg := p.cfg
+		rep.Config = &cfg
+		rep.Profile = p.trace.profile
+		for _, a := range p.additional {
+			rep.AdditionalFiles = append(rep.AdditionalFiles, a.GetRelativePath())
+		}
+		rep.Warnings = append(rep.Warnings, p.warnings...)
+	}
+	return rep
+}
+
+// writeReport encodes one project report as a single JSON document followed by a newline.
+func writeReport(w io.Writer, res projectResult) {
+	_ = json.NewEncoder(w).Encode(newProjectReport(res))
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputJSONSingleProject(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(dir, "go.mod"), sampleGoMod)
+	writeFile(t, filepath.Join(dir, ".dockerbuild"), "base:\n  image: alpine:3.20\n")
+
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"-p", dir, "--output", "json"})
+	out := captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	if strings.Contains(out, "Successfully generated") {
+		t.Fatalf("human-readable message must not go to stdout in json mode: %q", out)
+	}
+	var rep projectReport
+	if err := json.Unmarshal([]byte(out), &rep); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if rep.Language != "go" || rep.RepoRoot != dir || rep.Dockerfile != filepath.Join(dir, "Dockerfile") {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	if !rep.Changed || rep.Status != string(statusGenerated) {
+		t.Fatalf("expected changed generated report, got %+v", rep)
+	}
+	if rep.Config == nil || rep.Config.Base.Image != "alpine:3.20" {
+		t.Fatalf("expected effective config in report, got %+v", rep.Config)
+	}
+
+	cmd = newRootCmd()
+	cmd.SetArgs([]string{"-p", dir, "-o", "json"})
+	out = captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	rep = projectReport{}
+	if err := json.Unmarshal([]byte(out), &rep); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rep.Changed {
+		t.Fatalf("second generation should not report a change: %+v", rep)
+	}
+}
+
+func TestOutputJSONAll(t *testing.T) {
+	root := newMonorepo(t)
+	writeFile(t, filepath.Join(root, "broken", ".dockerbuild"), "base: [unclosed")
+	writeFile(t, filepath.Join(root, "broken", "go.mod"), sampleGoMod)
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"--all", "-p", root, "--dry-run", "-o", "json", "-j", "3"})
+	out := captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	dec := json.NewDecoder(strings.NewReader(out))
+	var reports []projectReport
+	for {
+		var rep projectReport
+		if err := dec.Decode(&rep); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("decode: %v\n%s", err, out)
+		}
+		reports = append(reports, rep)
+	}
+	if len(reports) != 4 {
+		t.Fatalf("expected 4 reports, got %d:\n%s", len(reports), out)
+	}
+	if reports[0].Project != filepath.Join(root, "broken") || len(reports[0].Warnings) != 1 {
+		t.Fatalf("expected config warning for broken project first, got %+v", reports[0])
+	}
+	for _, rep := range reports {
+		if rep.Status != string(statusChanged) || !rep.Changed {
+			t.Fatalf("expected dry-run change for every project, got %+v", rep)
+		}
+	}
+	if strings.Contains(out, "PROJECT") {
+		t.Fatalf("summary table must go to stderr in json mode")
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
+)
+
+// schemaFile is the committed copy of the JSON Schema shipped in release archives.
+const schemaFile = "schema/dockerbuild.schema.json"
+
+// newConfigCmd builds the 'config' command grouping configuration file helpers.
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Work with " + config.DefaultDockerBuildFileName + " configuration files",
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of " + config.DefaultDockerBuildFileName + " (for editor validation and completion)",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return writeSchema(os.Stdout)
+		},
+	})
+	return cmd
+}
+
+// writeSchema writes the indented JSON Schema for the registered languages.
+func writeSchema(out io.Writer) error {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(config.Schema(generator.Names())); err != nil {
+		return fmt.Errorf("error encoding schema: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestConfigSchemaMatchesCommittedFile(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeSchema(&b); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	committed, err := os.ReadFile(schemaFile)
+	if err != nil {
+		t.Fatalf("read %s: %v", schemaFile, err)
+	}
+	if !bytes.Equal(committed, b.Bytes()) {
+		t.Fatalf("%s is out of date; regenerate it with 'make schema'", schemaFile)
+	}
+}
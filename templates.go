@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/templates"
+)
+
+// newTemplatesCmd builds the 'templates' command grouping Dockerfile template helpers.
+func newTemplatesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "templates",
+		Short: "Work with Dockerfile templates (custom templates and hook files)",
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "functions",
+		Short: "List the functions available to Dockerfile templates and hook files",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			writeFunctions(os.Stdout)
+			return nil
+		},
+	}, newTemplatesExportCmd(), newTemplatesDiffCmd())
+	return cmd
+}
+
+// writeFunctions prints every template function with its signature, description and example.
+func writeFunctions(out io.Writer) {
+	for i, f := range templates.Functions() {
+		if i > 0 {
+			_, _ = fmt.Fprintln(out)
+		}
+		_, _ = fmt.Fprintln(out, f.Name)
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(tw, "  usage:\t%s\n", f.Signature)
+		_, _ = fmt.Fprintf(tw, "  description:\t%s\n", f.Description)
+		_, _ = fmt.Fprintf(tw, "  example:\t%s\n", f.Example)
+		_ = tw.Flush()
+	}
+}
+
+// newTemplatesExportCmd builds 'templates export' that writes an embedded template as the
+// starting point of a custom one.
+func newTemplatesExportCmd() *cobra.Command {
+	var output string
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "export <language>",
+		Short: "Write the embedded Dockerfile template of a generator, to customize it with the template key",
+		Long: `export writes the embedded Dockerfile template of a generator with a header recording the
+dockerfile-gen version and the hash of the template. Generation warns when a custom template with
+that header no longer matches the embedded template of the running binary; 'templates diff' shows
+the differences.`,
+		Args: cobra.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return templateLanguages(), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runTemplatesExport(os.Stdout, args[0], output, force)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVarP(&output, "output", "o", "", "File to write (default Dockerfile.<language>.tmpl; - for stdout)")
+	f.BoolVar(&force, "force", false, "Overwrite an existing file")
+	return cmd
+}
+
+// templateLanguages returns the names of the generators that have an embedded template.
+func templateLanguages() []string {
+	var names []string
+	for _, g := range generator.All() {
+		if _, ok := g.(generator.Templater); ok {
+			names = append(names, g.Name())
+		}
+	}
+	return names
+}
+
+func runTemplatesExport(out io.Writer, language, output string, force bool) error {
+	g, err := lookupGenerator(language)
+	if err != nil {
+		return err
+	}
+	t, ok := g.(generator.Templater)
+	if !ok {
+		return fmt.Errorf("generator %s has no embedded template (available: %s)", g.Name(), strings.Join(templateLanguages(), ", "))
+	}
+	text := templates.Export(g.Name(), version, t.Template())
+	if output == stdoutDest {
+		_, err := io.WriteString(out, text)
+		return err
+	}
+	if output == "" {
+		output = "Dockerfile." + g.Name() + ".tmpl"
+	}
+	if _, err := os.Stat(output); err == nil && !force {
+		return fmt.Errorf("%s already exists; use --force to overwrite", output)
+	}
+	if err := os.WriteFile(output, []byte(text), 0o600); err != nil {
+		return fmt.Errorf("error writing %s: %w", output, err)
+	}
+	_, _ = fmt.Fprintf(out, "Exported the %s template to %s; set 'template: %s' in %s to use it\n",
+		g.Name(), output, output, config.DefaultDockerBuildFileName)
+	return nil
+}
+
+// newTemplatesDiffCmd builds 'templates diff' that compares a project's custom template with the
+// embedded template it replaces.
+func newTemplatesDiffCmd() *cobra.Command {
+	var opts generateOptions
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show the differences between a project's custom template and the embedded template",
+		Long: `diff resolves the project like generate (config files, --profile, --template) and prints a unified
+diff from the embedded template of its generator (-) to the custom template in use (+), without the
+header written by 'templates export'.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runTemplatesDiff(os.Stdout, os.Stderr, opts)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVarP(&opts.projectPath, "path", "p", ".",
+		"Path to the project (directory, .csproj, or go.mod). Defaults to current directory.")
+	addLanguageFlag(f, &opts.language)
+	addConfigFlags(f, &opts)
+	registerCompletions(cmd)
+	return cmd
+}
+
+func runTemplatesDiff(out, errOut io.Writer, opts generateOptions) error {
+	p, err := newProjectRunner(out, errOut).resolveProject(opts, opts.projectPath)
+	if err != nil {
+		return err
+	}
+	if p.cfg.Template == "" {
+		return fmt.Errorf("no custom template in use; set the template key or pass --template")
+	}
+	t, ok := p.gen.(generator.Templater)
+	if !ok {
+		return fmt.Errorf("generator %s has no embedded template to compare with", p.gen.Name())
+	}
+	diff, err := templates.Diff(p.cfg.Template, t.Template())
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		_, _ = fmt.Fprintf(out, "%s matches the embedded %s template\n", p.cfg.Template, p.gen.Name())
+		return nil
+	}
+	_, _ = fmt.Fprintln(out, diff)
+	return nil
+}
+
+// templateDrift returns a warning when the custom template at path was exported from an embedded
+// template other than the one of gen, or "" (also when the template cannot be read: rendering
+// reports that).
+func templateDrift(gen generator.Generator, path string) string {
+	t, ok := gen.(generator.Templater)
+	if path == "" || !ok {
+		return ""
+	}
+	origin, drifted, err := templates.Drift(path, t.Template())
+	if err != nil || !drifted {
+		return ""
+	}
+	if origin.Language != gen.Name() {
+		return fmt.Sprintf("template %s was exported from the %s template but is used by the %s generator", path, origin.Language, gen.Name())
+	}
+	return fmt.Sprintf("template %s was exported from the %s template of dockerfile-gen %s, which has changed since; "+
+		"run 'dockerfile-gen templates diff' to review the differences", path, origin.Language, origin.Version)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/generator"
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/templates"
+)
+
+func TestTemplatesFunctionsCmd(t *testing.T) {
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"templates", "functions"})
+	out := captureStdout(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	for _, f := range templates.Functions() {
+		if !strings.Contains(out, f.Name+"\n") || !strings.Contains(out, f.Signature) {
+			t.Fatalf("expected function %s listed, got:\n%s", f.Name, out)
+		}
+	}
+	if !strings.Contains(out, "usage:        relPath BASE PATH -> STRING") {
+		t.Fatalf("expected aligned usage rows, got:\n%s", out)
+	}
+}
+
+func TestTemplatesExportAndDrift(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(dir, "go.mod"), sampleGoMod)
+	tmpl := filepath.Join(dir, "Dockerfile.go.tmpl")
+
+	var out bytes.Buffer
+	if err := runTemplatesExport(&out, "go", tmpl, false); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if err := runTemplatesExport(&out, "go", tmpl, false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected overwrite refusal, got %v", err)
+	}
+	if err := runTemplatesExport(&out, "rust", tmpl, true); err == nil {
+		t.Fatal("expected unsupported language error")
+	}
+	gen := generator.MustGet("go")
+	if w := templateDrift(gen, tmpl); w != "" {
+		t.Fatalf("fresh export should not warn, got %q", w)
+	}
+	out.Reset()
+	opts := generateOptions{projectPath: dir, template: tmpl}
+	if err := runTemplatesDiff(&out, io.Discard, opts); err != nil || !strings.Contains(out.String(), "matches the embedded go template") {
+		t.Fatalf("diff of fresh export: %v\n%s", err, out.String())
+	}
+
+	// Simulate an export by an older version whose embedded template differed.
+	content, _ := os.ReadFile(tmpl)
+	stale := regexp.MustCompile(`sha256:[0-9a-f]{64}`).ReplaceAllString(string(content), "sha256:"+strings.Repeat("0", 64))
+	stale = strings.Replace(stale, "WORKDIR /src", "WORKDIR /source", 1)
+	writeFile(t, tmpl, stale)
+	if w := templateDrift(gen, tmpl); !strings.Contains(w, "has changed since") || !strings.Contains(w, "templates diff") {
+		t.Fatalf("expected drift warning, got %q", w)
+	}
+	if w := templateDrift(generator.MustGet("dotnet"), tmpl); !strings.Contains(w, "exported from the go template but is used by the dotnet generator") {
+		t.Fatalf("expected language mismatch warning, got %q", w)
+	}
+
+	var stderr bytes.Buffer
+	p, err := newProjectRunner(io.Discard, &stderr).resolveProject(opts, dir)
+	if err != nil || !strings.Contains(stderr.String(), "Warning: template "+tmpl+" was exported") || len(p.warnings) != 1 {
+		t.Fatalf("expected drift warning, got %v\n%s", err, stderr.String())
+	}
+
+	out.Reset()
+	if err := runTemplatesDiff(&out, io.Discard, opts); err != nil || !strings.Contains(out.String(), "-WORKDIR /src\n+WORKDIR /source") {
+		t.Fatalf("expected template diff, got %v\n%s", err, out.String())
+	}
+	if err := runTemplatesDiff(&out, io.Discard, generateOptions{projectPath: dir}); err == nil || !strings.Contains(err.Error(), "no custom template") {
+		t.Fatalf("expected error without template, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/n2jsoft-public-org/dockerfile-generator/internal/config"
+)
+
+// newWatchCmd builds the 'watch' subcommand that regenerates a Dockerfile whenever its inputs change.
+func newWatchCmd() *cobra.Command {
+	var opts generateOptions
+	var interval time.Duration
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Poll the project's inputs and regenerate (or diff) the Dockerfile whenever they change",
+		Long: `watch runs a generation, then polls every file that affected it (project files such as the
+.csproj graph or go.mod/go.sum, Directory.*.props, nuget.config and .dockerbuild) and runs again
+when one changes. The watched set is recomputed after every run, so new references are picked up.
+Stop with Ctrl-C.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			return watchLoop(ctx, newProjectRunner(os.Stdout, os.Stderr), opts, interval, nil)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVarP(&opts.projectPath, "path", "p", ".",
+		"Path to the project (directory, .csproj, or go.mod). Defaults to current directory.")
+	f.StringVarP(&opts.dockerfileName, "dockerfile", "f", "Dockerfile", "Name of the Dockerfile to generate")
+	addLanguageFlag(f, &opts.language)
+	addConfigFlags(f, &opts)
+	f.BoolVarP(&opts.dryRun, "dry-run", "d", false, "Do not write file; show the diff on every change")
+	f.DurationVar(&interval, "interval", time.Second, "Polling interval")
+	registerCompletions(cmd)
+	return cmd
+}
+
+// fileState is the polled state of one watched file.
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// snapshot records the current state of files; missing files are recorded so their creation is noticed.
+func snapshot(files []string) map[string]fileState {
+	snap := make(map[string]fileState, len(files))
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil {
+			snap[f] = fileState{}
+			continue
+		}
+		snap[f] = fileState{exists: true, size: fi.Size(), modTime: fi.ModTime()}
+	}
+	return snap
+}
+
+// changedFile returns the first file whose state differs between two snapshots of the same set.
+func changedFile(before, after map[string]fileState) (string, bool) {
+	for f, st := range before {
+		if after[f] != st {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// baseline returns the state polls of files are compared with: the state taken before the run for
+// the files it already watched, and the current state for the files the run added.
+func baseline(files []string, before map[string]fileState) map[string]fileState {
+	var added []string
+	for _, f := range files {
+		if _, ok := before[f]; !ok {
+			added = append(added, f)
+		}
+	}
+	state := snapshot(added)
+	for _, f := range files {
+		if st, ok := before[f]; ok {
+			state[f] = st
+		}
+	}
+	return state
+}
+
+// watchLoop generates once, then regenerates each time a watched input changes until ctx is done.
+// onRun, when non-nil, is called after every run (used by tests).
+func watchLoop(ctx context.Context, r *projectRunner, opts generateOptions, interval time.Duration,
+	onRun func(projectResult)) error {
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	if opts.dockerfileName == stdoutDest {
+		return fmt.Errorf("--dockerfile - (stdout) cannot be used with watch")
+	}
+	// Inputs are snapshotted before each run, so an edit made while it runs triggers another one.
+	files := watchedFiles(opts, projectResult{})
+	for {
+		before := snapshot(files)
+		res := r.runProject(opts, opts.projectPath)
+		files = watchedFiles(opts, res)
+		state := baseline(files, before)
+		if res.err != nil {
+			_, _ = fmt.Fprintf(r.errOut, "Error: %v\n", res.err)
+		}
+		if onRun != nil {
+			onRun(res)
+		}
+		r.infof("watching %d file(s) for changes", len(files))
+		for _, f := range files {
+			r.debugf("watching %s", f)
+		}
+
+		ticker := time.NewTicker(interval)
+	poll:
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return nil
+			case <-ticker.C:
+				if f, ok := changedFile(state, snapshot(files)); ok {
+					_, _ = fmt.Fprintf(r.out, "Change detected in %s; regenerating\n", f)
+					break poll
+				}
+			}
+		}
+		ticker.Stop()
+	}
+}
+
+// watchedFiles returns the inputs of the last run. When the project could not be resolved,
+// the project path and its directory's config files (or the --config file) are watched so a fix
+// is picked up.
+func watchedFiles(opts generateOptions, res projectResult) []string {
+	if res.project != nil {
+		return res.project.inputs()
+	}
+	dir := opts.projectPath
+	if fi, err := os.Stat(dir); err == nil && !fi.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	files := []string{opts.projectPath}
+	if opts.configPath != "" {
+		return append(files, opts.configPath)
+	}
+	for _, name := range config.FileNames {
+		files = append(files, filepath.Join(dir, name))
+	}
+	return files
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchLoopPicksUpNewReferences(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+	app := filepath.Join(root, "App", "App.csproj")
+	lib := filepath.Join(root, "Lib", "Lib.csproj")
+	writeFile(t, app, sampleCsproj)
+	writeFile(t, lib, sampleCsproj)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runs := make(chan projectResult)
+	done := make(chan error, 1)
+	opts := generateOptions{projectPath: app, dockerfileName: "Dockerfile"}
+	go func() {
+		done <- watchLoop(ctx, newProjectRunner(io.Discard, io.Discard), opts, 5*time.Millisecond,
+			func(res projectResult) { runs <- res })
+	}()
+	next := func() projectResult {
+		t.Helper()
+		select {
+		case res := <-runs:
+			if res.err != nil {
+				t.Fatalf("run failed: %v", res.err)
+			}
+			return res
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for a watch run")
+		}
+		return projectResult{}
+	}
+
+	first := next()
+	if slices.Contains(first.project.inputs(), lib) {
+		t.Fatalf("Lib should not be watched before it is referenced")
+	}
+	if !slices.Contains(first.project.inputs(), filepath.Join(root, "App", ".dockerbuild")) {
+		t.Fatalf("missing .dockerbuild should be watched: %v", first.project.inputs())
+	}
+
+	writeFile(t, app, `<Project><ItemGroup><ProjectReference Include="../Lib/Lib.csproj" /></ItemGroup></Project>`)
+	second := next()
+	if !slices.Contains(second.project.inputs(), lib) {
+		t.Fatalf("expected Lib to be watched after the reference was added: %v", second.project.inputs())
+	}
+
+	writeFile(t, lib, sampleCsproj+"\n<!-- touched -->\n")
+	next()
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("watch returned error: %v", err)
+	}
+}
+
+func TestWatchLoopRejectsStdout(t *testing.T) {
+	opts := generateOptions{projectPath: t.TempDir(), dockerfileName: stdoutDest}
+	err := watchLoop(context.Background(), newProjectRunner(io.Discard, io.Discard), opts, time.Millisecond, nil)
+	if err == nil || !strings.Contains(err.Error(), "--dockerfile - (stdout)") {
+		t.Fatalf("expected --dockerfile - to be rejected, got %v", err)
+	}
+}
+
+func TestChangedFile(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "go.mod")
+	before := snapshot([]string{f})
+	if _, ok := changedFile(before, snapshot([]string{f})); ok {
+		t.Fatalf("no change expected")
+	}
+	writeFile(t, f, sampleGoMod)
+	if got, ok := changedFile(before, snapshot([]string{f})); !ok || got != f {
+		t.Fatalf("expected creation of %s to be detected, got %q %v", f, got, ok)
+	}
+}
+
+func TestBaselineKeepsStateFromBeforeTheRun(t *testing.T) {
+	dir := t.TempDir()
+	known := filepath.Join(dir, "go.mod")
+	added := filepath.Join(dir, ".dockerbuild")
+	before := snapshot([]string{known})
+	writeFile(t, known, sampleGoMod) // edited while the run was in progress
+	writeFile(t, added, "base:\n  image: alpine:3.20\n")
+
+	state := baseline([]string{known, added}, before)
+	if got, ok := changedFile(state, snapshot([]string{known, added})); !ok || got != known {
+		t.Fatalf("expected the edit made during the run to be detected, got %q %v", got, ok)
+	}
+	if state[added] != snapshot([]string{added})[added] {
+		t.Fatalf("expected files added by the run to start from their current state")
+	}
+}